@@ -0,0 +1,130 @@
+// Package auth authenticates requests carrying JWT bearer tokens.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+const (
+	HeaderAuthorization   = "Authorization"
+	HeaderWWWAuthenticate = "WWW-Authenticate"
+)
+
+// ErrMissingToken is returned when a request has no bearer token.
+var ErrMissingToken = errors.New("missing bearer token")
+
+type claimsKey struct{}
+
+// BearerToken returns the token of an Authorization: Bearer header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get(HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// Options configures the Authenticate middleware.
+type Options struct {
+	Verifier *Verifier
+
+	// Realm is sent in the WWW-Authenticate challenge.
+	Realm string
+
+	// Optional lets requests without a token through unauthenticated.
+	// Requests with an invalid token are still rejected.
+	Optional bool
+}
+
+// Authenticate verifies the bearer token of every request and decodes its
+// claims into a T, available to handlers through Claims. T is usually a struct
+// embedding RegisteredClaims. Failures are answered with 401 problems
+// carrying a WWW-Authenticate challenge, as described in RFC 6750.
+func Authenticate[T any](opts Options) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := BearerToken(r)
+			if !ok {
+				if opts.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				unauthorized(w, opts.Realm, ErrMissingToken)
+				return
+			}
+
+			var claims T
+			if err := opts.Verifier.Verify(token, &claims); err != nil {
+				slog.Info("authentication failed", "reason", err, "method", r.Method, "path", r.URL.Path)
+				unauthorized(w, opts.Realm, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, claims)))
+		})
+	}
+}
+
+// Claims returns the claims of an authenticated request.
+// It reports false when the request was not authenticated or when T is not
+// the type given to Authenticate.
+func Claims[T any](r *http.Request) (T, bool) {
+	claims, ok := r.Context().Value(claimsKey{}).(T)
+	return claims, ok
+}
+
+// unauthorized sends a 401 problem with a bearer challenge.
+// A missing token gets a challenge without an error code, as RFC 6750 requires.
+func unauthorized(w http.ResponseWriter, realm string, err error) {
+	var params []string
+	if realm != "" {
+		params = append(params, `realm="`+quote(realm)+`"`)
+	}
+
+	detail := "The request requires a bearer token."
+	if !errors.Is(err, ErrMissingToken) {
+		detail = "The bearer token is invalid."
+		params = append(params, `error="invalid_token"`, `error_description="`+quote(describe(err))+`"`)
+	}
+
+	challenge := "Bearer"
+	if len(params) > 0 {
+		challenge += " " + strings.Join(params, ", ")
+	}
+
+	w.Header().Set(HeaderWWWAuthenticate, challenge)
+	response.Unauthorized(w, detail)
+}
+
+// describe returns a short reason that reveals nothing about the keys.
+func describe(err error) string {
+	switch {
+	case errors.Is(err, ErrTokenExpired):
+		return "The token expired"
+	case errors.Is(err, ErrTokenNotYetValid):
+		return "The token is not valid yet"
+	case errors.Is(err, ErrInvalidIssuer):
+		return "The token has the wrong issuer"
+	case errors.Is(err, ErrInvalidAudience):
+		return "The token has the wrong audience"
+	case errors.Is(err, ErrUnsupportedAlgorithm):
+		return "The token algorithm is not supported"
+	case errors.Is(err, ErrMalformedToken):
+		return "The token is malformed"
+	default:
+		return "The token signature is invalid"
+	}
+}
+
+func quote(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
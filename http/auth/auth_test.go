@@ -0,0 +1,103 @@
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/auth"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"Bearer abc.def.ghi", "abc.def.ghi", true},
+		{"bearer abc", "abc", true},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"Bearer ", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(auth.HeaderAuthorization, tt.header)
+
+		got, ok := auth.BearerToken(req)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%q: got %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		token     string
+		optional  bool
+		status    int
+		challenge string
+	}{
+		{"valid", sign(t, auth.EdDSA, "ed", validClaims()), false, http.StatusOK, ""},
+		{"missing", "", false, http.StatusUnauthorized, `Bearer realm="api"`},
+		{"missing optional", "", true, http.StatusNoContent, ""},
+		{"expired", sign(t, auth.HS256, "", expired), true, http.StatusUnauthorized,
+			`Bearer realm="api", error="invalid_token", error_description="The token expired"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := auth.Authenticate[userClaims](auth.Options{
+				Verifier: newVerifier(t),
+				Realm:    "api",
+				Optional: tt.optional,
+			})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := auth.Claims[userClaims](r)
+				if !ok {
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				_, _ = w.Write([]byte(claims.Subject + " " + claims.Role))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				req.Header.Set(auth.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Fatalf("got status %d, want %d", rr.Code, tt.status)
+			}
+			if got := rr.Header().Get(auth.HeaderWWWAuthenticate); got != tt.challenge {
+				t.Errorf("got challenge %q, want %q", got, tt.challenge)
+			}
+
+			switch tt.status {
+			case http.StatusOK:
+				if got := rr.Body.String(); got != "user-1 admin" {
+					t.Errorf("got body %q", got)
+				}
+			case http.StatusUnauthorized:
+				if got := rr.Header().Get(ghttp.HeaderContentType); !strings.HasPrefix(got, ghttp.MimeProblemJSON) {
+					t.Errorf("got content type %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestClaimsWithoutAuthentication(t *testing.T) {
+	if _, ok := auth.Claims[userClaims](httptest.NewRequest(http.MethodGet, "/", nil)); ok {
+		t.Error("unauthenticated request should have no claims")
+	}
+}
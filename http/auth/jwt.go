@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const defaultLeeway = time.Minute
+
+var (
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenNotYetValid     = errors.New("token not yet valid")
+	ErrInvalidIssuer        = errors.New("invalid issuer")
+	ErrInvalidAudience      = errors.New("invalid audience")
+)
+
+// NumericDate is a JWT timestamp, in seconds since the epoch.
+type NumericDate struct {
+	time.Time
+}
+
+func (d *NumericDate) UnmarshalJSON(b []byte) error {
+	var secs float64
+	if err := json.Unmarshal(b, &secs); err != nil {
+		return fmt.Errorf("numeric date: %w", err)
+	}
+	whole, frac := math.Modf(secs)
+	d.Time = time.Unix(int64(whole), int64(frac*1e9))
+	return nil
+}
+
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Unix())
+}
+
+// Audience is the aud claim, which may be a single string or an array.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*a = Audience{s}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(b, &list); err != nil {
+		return fmt.Errorf("audience: %w", err)
+	}
+	*a = list
+	return nil
+}
+
+// RegisteredClaims are the standard claims of RFC 7519.
+// Embed it in a claims type to read custom claims along with them.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// Verifier checks the signature and registered claims of JWTs.
+// Tokens without an exp claim are accepted unless RequireExpiry is set.
+type Verifier struct {
+	Keys *KeySet
+
+	// Issuer, when set, must equal the iss claim.
+	Issuer string
+
+	// Audience, when set, must be one of the aud claims.
+	Audience string
+
+	// Leeway allows for clock skew when checking exp and nbf.
+	// Defaults to one minute.
+	Leeway time.Duration
+
+	// RequireExpiry rejects tokens without an exp claim.
+	RequireExpiry bool
+}
+
+// Verify checks a compact JWT and decodes its claims into claims.
+func (v *Verifier) Verify(token string, claims any) error {
+	payload, err := v.verifySignature(token)
+	if err != nil {
+		return err
+	}
+
+	var registered RegisteredClaims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return fmt.Errorf("%w: claims: %v", ErrMalformedToken, err)
+	}
+	if err := v.validate(&registered, time.Now()); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return fmt.Errorf("%w: claims: %v", ErrMalformedToken, err)
+	}
+	return nil
+}
+
+// verifySignature returns the payload of a token signed by one of the keys.
+func (v *Verifier) verifySignature(token string) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	rawHeader, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: header", ErrMalformedToken)
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return nil, fmt.Errorf("%w: header", ErrMalformedToken)
+	}
+
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature", ErrMalformedToken)
+	}
+
+	switch header.Alg {
+	case HS256, RS256, EdDSA:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, k := range v.Keys.candidates(header.Kid, header.Alg) {
+		if verifyWith(k, signed, sig) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload", ErrMalformedToken)
+	}
+	return payload, nil
+}
+
+func verifyWith(k Key, signed, sig []byte) bool {
+	switch key := k.Material.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write(signed)
+		return hmac.Equal(sig, mac.Sum(nil))
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(signed)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, signed, sig)
+	default:
+		return false
+	}
+}
+
+func (v *Verifier) validate(c *RegisteredClaims, now time.Time) error {
+	leeway := v.Leeway
+	if leeway == 0 {
+		leeway = defaultLeeway
+	}
+
+	if c.ExpiresAt == nil && v.RequireExpiry {
+		return fmt.Errorf("%w: no exp claim", ErrTokenExpired)
+	}
+	if c.ExpiresAt != nil && !now.Before(c.ExpiresAt.Add(leeway)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != nil && now.Add(leeway).Before(c.NotBefore.Time) {
+		return ErrTokenNotYetValid
+	}
+	if v.Issuer != "" && c.Issuer != v.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.Audience != "" && !contains(c.Audience, v.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
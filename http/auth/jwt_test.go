@@ -0,0 +1,224 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/auth"
+)
+
+var (
+	hmacSecret       = []byte("0123456789abcdef0123456789abcdef")
+	rsaKey, _        = rsa.GenerateKey(rand.Reader, 2048)
+	edPub, edPriv, _ = ed25519.GenerateKey(rand.Reader)
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sign builds a compact JWT signed with the private key for alg.
+func sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+	h, _ := json.Marshal(header)
+	p, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := b64(h) + "." + b64(p)
+
+	var sig []byte
+	switch alg {
+	case auth.HS256:
+		mac := hmac.New(sha256.New, hmacSecret)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case auth.RS256:
+		digest := sha256.Sum256([]byte(signed))
+		sig, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+	case auth.EdDSA:
+		sig = ed25519.Sign(edPriv, []byte(signed))
+	}
+
+	return signed + "." + b64(sig)
+}
+
+func newVerifier(t *testing.T) *auth.Verifier {
+	t.Helper()
+
+	keys, err := auth.NewKeySet(
+		auth.Key{ID: "hmac", Material: hmacSecret},
+		auth.Key{ID: "rsa", Material: &rsaKey.PublicKey},
+		auth.Key{ID: "ed", Material: edPub},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &auth.Verifier{Keys: keys, Issuer: "https://issuer.example.com", Audience: "api"}
+}
+
+type userClaims struct {
+	auth.RegisteredClaims
+	Role string `json:"role"`
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"iss":  "https://issuer.example.com",
+		"sub":  "user-1",
+		"aud":  []string{"web", "api"},
+		"exp":  time.Now().Add(time.Hour).Unix(),
+		"nbf":  time.Now().Add(-time.Minute).Unix(),
+		"role": "admin",
+	}
+}
+
+func TestVerifyAlgorithms(t *testing.T) {
+	v := newVerifier(t)
+
+	for _, alg := range []string{auth.HS256, auth.RS256, auth.EdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			var claims userClaims
+			if err := v.Verify(sign(t, alg, "", validClaims()), &claims); err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if claims.Subject != "user-1" || claims.Role != "admin" || claims.ExpiresAt == nil {
+				t.Errorf("got claims %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	v := newVerifier(t)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		change func(map[string]any)
+		want   error
+	}{
+		{"expired", func(c map[string]any) { c["exp"] = now.Add(-2 * time.Minute).Unix() }, auth.ErrTokenExpired},
+		{"expired within leeway", func(c map[string]any) { c["exp"] = now.Add(-30 * time.Second).Unix() }, nil},
+		{"not yet valid", func(c map[string]any) { c["nbf"] = now.Add(2 * time.Minute).Unix() }, auth.ErrTokenNotYetValid},
+		{"not yet valid within leeway", func(c map[string]any) { c["nbf"] = now.Add(30 * time.Second).Unix() }, nil},
+		{"issuer", func(c map[string]any) { c["iss"] = "https://evil.com" }, auth.ErrInvalidIssuer},
+		{"audience", func(c map[string]any) { c["aud"] = "web" }, auth.ErrInvalidAudience},
+		{"single audience", func(c map[string]any) { c["aud"] = "api" }, nil},
+		{"no expiry", func(c map[string]any) { delete(c, "exp") }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			tt.change(claims)
+
+			var got userClaims
+			err := v.Verify(sign(t, auth.HS256, "", claims), &got)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	v.RequireExpiry = true
+	claims := validClaims()
+	delete(claims, "exp")
+	if err := v.Verify(sign(t, auth.HS256, "", claims), &userClaims{}); !errors.Is(err, auth.ErrTokenExpired) {
+		t.Errorf("got error %v for a token without exp", err)
+	}
+}
+
+func TestVerifyRejectsForgeries(t *testing.T) {
+	v := newVerifier(t)
+	valid := sign(t, auth.HS256, "", validClaims())
+	parts := strings.Split(valid, ".")
+
+	// An RS256 token signed with the RSA public key as an HMAC secret.
+	rsaPub := rsaKey.PublicKey.N.Bytes()
+	confused := func() string {
+		h := b64([]byte(`{"alg":"HS256","kid":"rsa"}`))
+		mac := hmac.New(sha256.New, rsaPub)
+		mac.Write([]byte(h + "." + parts[1]))
+		return h + "." + parts[1] + "." + b64(mac.Sum(nil))
+	}()
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"none algorithm", b64([]byte(`{"alg":"none"}`)) + "." + parts[1] + ".", auth.ErrUnsupportedAlgorithm},
+		{"tampered payload", parts[0] + "." + b64([]byte(`{"sub":"root"}`)) + "." + parts[2], auth.ErrInvalidSignature},
+		{"wrong kid", sign(t, auth.HS256, "rsa", validClaims()), auth.ErrInvalidSignature},
+		{"algorithm confusion", confused, auth.ErrInvalidSignature},
+		{"two segments", parts[0] + "." + parts[1], auth.ErrMalformedToken},
+		{"garbage", "a.b.c", auth.ErrMalformedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.Verify(tt.token, &userClaims{}); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	e := big.NewInt(int64(rsaKey.PublicKey.E)).Bytes()
+	doc, _ := json.Marshal(map[string]any{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "alg": "RS256", "n": b64(rsaKey.PublicKey.N.Bytes()), "e": b64(e)},
+			{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(edPub)},
+			{"kty": "oct", "kid": "hmac", "k": b64(hmacSecret)},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+			{"kty": "EC", "kid": "ec", "use": "sig", "alg": "ES256", "crv": "P-256", "x": "AA", "y": "AA"},
+			{"kty": "OKP", "kid": "x25519", "crv": "X25519", "x": "AA"},
+		},
+	})
+
+	keys, err := auth.ParseJWKS(doc)
+	if err != nil {
+		t.Fatalf("parse jwks: %v", err)
+	}
+
+	v := &auth.Verifier{Keys: keys}
+	for _, tt := range []struct{ alg, kid string }{{auth.RS256, "rsa"}, {auth.EdDSA, "ed"}, {auth.HS256, "hmac"}} {
+		if err := v.Verify(sign(t, tt.alg, tt.kid, validClaims()), &userClaims{}); err != nil {
+			t.Errorf("%s: %v", tt.alg, err)
+		}
+	}
+
+	invalid := []string{
+		`{"keys":[{"kty":"RSA","n":"","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"oct","k":"!!"}]}`,
+		`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AA"}]}`,
+		`{"keys":[{"kty":"RSA","alg":"HS256","n":"AQAB","e":"AQAB"}]}`,
+		`not json`,
+	}
+	for _, doc := range invalid {
+		if _, err := auth.ParseJWKS([]byte(doc)); err == nil {
+			t.Errorf("%s: expected an error", doc)
+		}
+	}
+}
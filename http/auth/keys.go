@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// Signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key is a verification key.
+// Material is a []byte secret for HS256, an *rsa.PublicKey for RS256 or an
+// ed25519.PublicKey for EdDSA; the algorithm follows from its type, so a key
+// can never be used with another algorithm.
+type Key struct {
+	// ID matches the kid header of tokens. Keys without an ID match any token.
+	ID       string
+	Material any
+}
+
+func (k Key) algorithm() string {
+	switch k.Material.(type) {
+	case []byte:
+		return HS256
+	case *rsa.PublicKey:
+		return RS256
+	case ed25519.PublicKey:
+		return EdDSA
+	default:
+		return ""
+	}
+}
+
+// KeySet holds the keys tokens are verified against.
+type KeySet struct {
+	keys []Key
+}
+
+// Creates a KeySet from static keys
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	for _, k := range keys {
+		if k.algorithm() == "" {
+			return nil, fmt.Errorf("key %q: unsupported key type %T", k.ID, k.Material)
+		}
+	}
+	return &KeySet{keys: keys}, nil
+}
+
+// candidates returns the keys that may have signed a token with kid and alg.
+func (ks *KeySet) candidates(kid, alg string) []Key {
+	var keys []Key
+	for _, k := range ks.keys {
+		if k.algorithm() != alg {
+			continue
+		}
+		if kid != "" && k.ID != "" && k.ID != kid {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// jwk is a JSON Web Key as defined by RFC 7517.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// oct
+	K string `json:"k"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// ParseJWKS reads the signing keys of a JWKS document.
+// Encryption keys and keys of unsupported types or curves are skipped, so a
+// provider that also publishes, say, EC keys still yields its usable ones.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+
+	var keys []Key
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" || !k.supported() {
+			continue
+		}
+
+		material, err := k.material()
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %d (%s): %w", i, k.Kid, err)
+		}
+
+		key := Key{ID: k.Kid, Material: material}
+		if k.Alg != "" && k.Alg != key.algorithm() {
+			return nil, fmt.Errorf("jwks key %d (%s): algorithm %s does not match key type %s", i, k.Kid, k.Alg, k.Kty)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(keys...)
+}
+
+// LoadJWKS reads the signing keys of a JWKS file.
+func LoadJWKS(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+	return ParseJWKS(data)
+}
+
+// supported reports whether the key type and curve can be verified.
+func (k jwk) supported() bool {
+	switch k.Kty {
+	case "oct", "RSA":
+		return true
+	case "OKP":
+		return k.Crv == "Ed25519"
+	default:
+		return false
+	}
+}
+
+func (k jwk) material() (any, error) {
+	switch k.Kty {
+	case "oct":
+		secret, err := decodeSegment(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, errors.New("invalid k")
+		}
+		return secret, nil
+
+	case "RSA":
+		n, err := decodeSegment(k.N)
+		if err != nil || len(n) == 0 {
+			return nil, errors.New("invalid n")
+		}
+		e, err := decodeSegment(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid e")
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeSegment(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid x")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+// ErrNotFound is a sentinel that handlers can return or wrap to send a 404.
+var ErrNotFound = errors.New("not found")
+
+// HandlerFunc is an http handler that returns an error instead of writing it.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls f and maps the returned error to a response.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tw := &trackingWriter{ResponseWriter: w}
+	if err := f(tw, r); err != nil {
+		if tw.wroteHeader {
+			slog.Error("handler error after response was written", "reason", err, "method", r.Method, "path", r.URL.Path)
+			return
+		}
+		HandleError(w, r, err)
+	}
+}
+
+// Adapt converts an error-returning handler into an http.Handler.
+func Adapt(f func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return HandlerFunc(f)
+}
+
+// Error is an error that carries the HTTP status to respond with.
+// Message is sent to the client; Err is only logged.
+type Error struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+// Creates an error with the given status and client-facing message
+func NewError(status int, message string, err error) *Error {
+	return &Error{Status: status, Message: message, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %v", e.Status, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HandleError writes the response that corresponds to err.
+// Unrecognized errors are sent through response.ServerError.
+func HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	var httpErr *Error
+	if errors.As(err, &httpErr) {
+		if httpErr.Status >= http.StatusInternalServerError {
+			slog.Error("handler error", "status", httpErr.Status, "reason", err, "method", r.Method, "path", r.URL.Path)
+		}
+		response.WriteProblem(w, response.NewProblem(httpErr.Status, httpErr.Message))
+		return
+	}
+
+	if p, ok := response.ProblemFromError(err); ok {
+		response.WriteProblem(w, p)
+		return
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled) && r.Context().Err() != nil:
+		// The client went away; there is no one to respond to.
+		slog.Debug("request canceled", "method", r.Method, "path", r.URL.Path)
+	case errors.Is(err, context.DeadlineExceeded):
+		slog.Warn("request timed out", "reason", err, "method", r.Method, "path", r.URL.Path)
+		response.WriteProblem(w, response.NewProblem(http.StatusServiceUnavailable, "The request timed out."))
+	case errors.Is(err, ErrNotFound), errors.Is(err, sql.ErrNoRows), errors.Is(err, fs.ErrNotExist):
+		response.NotFound(w, "The requested resource was not found.")
+	default:
+		response.ServerError(w, err)
+	}
+}
+
+// trackingWriter records whether the handler started the response.
+type trackingWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *trackingWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *trackingWriter) Flush() {
+	w.wroteHeader = true
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
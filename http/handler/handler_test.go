@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/handler"
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+func TestHandlerFuncMapsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		status      int
+		contentType string
+		body        string
+	}{
+		{"typed error", handler.NewError(http.StatusConflict, "Already exists.", errors.New("duplicate key")), http.StatusConflict, ghttp.MimeProblemJSON, "Already exists."},
+		{"validation error", request.ValidationError{"name": "is required"}, http.StatusUnprocessableEntity, ghttp.MimeProblemJSON, `"name":"is required"`},
+		{"not found sentinel", fmt.Errorf("find user: %w", handler.ErrNotFound), http.StatusNotFound, ghttp.MimeProblemJSON, "not found"},
+		{"no rows", fmt.Errorf("query: %w", sql.ErrNoRows), http.StatusNotFound, ghttp.MimeProblemJSON, "not found"},
+		{"deadline", context.DeadlineExceeded, http.StatusServiceUnavailable, ghttp.MimeProblemJSON, "timed out"},
+		{"unexpected", errors.New("boom"), http.StatusInternalServerError, "text/plain; charset=utf-8", "An error occurred."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				return tt.err
+			})
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if rr.Code != tt.status {
+				t.Errorf("got status %d, want %d", rr.Code, tt.status)
+			}
+			if ct := rr.Header().Get(ghttp.HeaderContentType); ct != tt.contentType {
+				t.Errorf("got content type %q, want %q", ct, tt.contentType)
+			}
+			if !strings.Contains(rr.Body.String(), tt.body) {
+				t.Errorf("body %q should contain %q", rr.Body.String(), tt.body)
+			}
+			if strings.Contains(rr.Body.String(), "duplicate key") {
+				t.Errorf("body should not leak the wrapped error: %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerFuncCanceledRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := handler.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		return r.Context().Err()
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+	if rr.Body.Len() != 0 {
+		t.Errorf("canceled request should not get a body, got %q", rr.Body.String())
+	}
+}
+
+func TestHandlerFuncErrorAfterWrite(t *testing.T) {
+	var buf bytes.Buffer
+	oldHandler := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(oldHandler)
+
+	h := handler.Adapt(func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusAccepted)
+		return errors.New("late failure")
+	})
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rr.Code != http.StatusAccepted {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusAccepted)
+	}
+	if !strings.Contains(buf.String(), "late failure") {
+		t.Errorf("error should be logged, got %q", buf.String())
+	}
+}
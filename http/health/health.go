@@ -0,0 +1,261 @@
+// Package health serves liveness and readiness endpoints backed by named checks.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+const (
+	defaultTimeout  = 5 * time.Second
+	defaultCacheTTL = 2 * time.Second
+)
+
+// Statuses reported for the whole service and for every check
+const (
+	StatusOK           = "ok"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckFunc reports whether a dependency is usable.
+// It should give up when ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named check registered by a component.
+type Check struct {
+	Name string
+	Func CheckFunc
+
+	// Timeout defaults to Checker.Timeout.
+	Timeout time.Duration
+
+	// Liveness also runs the check on /healthz. Only use it for failures that
+	// restarting the process fixes; everything else belongs to readiness.
+	Liveness bool
+}
+
+// Result is the outcome of a check.
+// Error is only set when Checker.ShowErrors is enabled.
+type Result struct {
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report is the JSON body of the health endpoints.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Checker runs the registered checks.
+// Results are cached for CacheTTL, so frequent probes do not overload the
+// dependencies, and a check never runs more than once at a time.
+type Checker struct {
+	// Timeout bounds checks without a timeout of their own. Defaults to 5 seconds.
+	Timeout time.Duration
+
+	// CacheTTL is how long results are reused. Defaults to 2 seconds;
+	// a negative value disables caching.
+	CacheTTL time.Duration
+
+	// ShowErrors adds check errors to the reports. Errors often name hosts
+	// or include connection strings, so only enable it when the endpoints
+	// are not publicly reachable. Failures are logged either way.
+	ShowErrors bool
+
+	mu           sync.RWMutex
+	entries      []*entry
+	shuttingDown atomic.Bool
+}
+
+// entry caches the last result of a check.
+type entry struct {
+	Check
+
+	mu        sync.Mutex
+	result    Result
+	checkedAt time.Time
+}
+
+// Creates a Checker without checks
+func New() *Checker {
+	return &Checker{
+		Timeout:  defaultTimeout,
+		CacheTTL: defaultCacheTTL,
+	}
+}
+
+// Register adds checks. Names should be unique.
+func (c *Checker) Register(checks ...Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, check := range checks {
+		c.entries = append(c.entries, &entry{Check: check})
+	}
+}
+
+// Shutdown makes readiness fail from now on, so no new traffic is routed to
+// the instance while it drains. It fits server.Options.BeforeShutdown.
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+}
+
+// Liveness runs the liveness checks.
+func (c *Checker) Liveness(ctx context.Context) Report {
+	return c.run(ctx, true)
+}
+
+// Readiness runs every check. It fails without running them once Shutdown was called.
+func (c *Checker) Readiness(ctx context.Context) Report {
+	if c.shuttingDown.Load() {
+		return Report{Status: StatusShuttingDown}
+	}
+	return c.run(ctx, false)
+}
+
+// LivenessHandler serves the liveness report, with 503 when it fails.
+func (c *Checker) LivenessHandler() http.Handler {
+	return reportHandler(c.Liveness)
+}
+
+// ReadinessHandler serves the readiness report, with 503 when it fails.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return reportHandler(c.Readiness)
+}
+
+// Mount serves liveness on GET /healthz and readiness on GET /readyz.
+func (c *Checker) Mount(mux *http.ServeMux) {
+	mux.Handle("GET /healthz", c.LivenessHandler())
+	mux.Handle("GET /readyz", c.ReadinessHandler())
+}
+
+func reportHandler(report func(context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rep := report(r.Context())
+
+		status := http.StatusOK
+		if rep.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		response.JSON(w, status, rep)
+	})
+}
+
+// run runs the selected checks concurrently.
+func (c *Checker) run(ctx context.Context, livenessOnly bool) Report {
+	c.mu.RLock()
+	var entries []*entry
+	for _, e := range c.entries {
+		if !livenessOnly || e.Liveness {
+			entries = append(entries, e)
+		}
+	}
+	c.mu.RUnlock()
+
+	rep := Report{Status: StatusOK}
+	if len(entries) == 0 {
+		return rep
+	}
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.check(ctx, e)
+		}()
+	}
+	wg.Wait()
+
+	rep.Checks = make(map[string]Result, len(entries))
+	for i, e := range entries {
+		rep.Checks[e.Name] = results[i]
+		if results[i].Status != StatusOK {
+			rep.Status = StatusFail
+		}
+	}
+	return rep
+}
+
+// check returns the cached result of e, running it first when the result is stale.
+func (c *Checker) check(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ttl := c.CacheTTL
+	if ttl == 0 {
+		ttl = defaultCacheTTL
+	}
+	if !e.checkedAt.IsZero() && time.Since(e.checkedAt) < ttl {
+		return e.result
+	}
+
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = c.Timeout
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	start := time.Now()
+	err := runCheck(ctx, e.Func, timeout)
+
+	e.result = Result{
+		Status:    StatusOK,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		e.result.Status = StatusFail
+		if c.ShowErrors {
+			e.result.Error = err.Error()
+		}
+		slog.Warn("health check failed", "check", e.Name, "reason", err)
+	}
+
+	// A check cut short by the caller going away says nothing about the dependency.
+	if ctx.Err() == nil {
+		e.checkedAt = time.Now()
+	}
+	return e.result
+}
+
+// runCheck runs fn with a deadline, returning when it passes even if fn ignores ctx.
+func runCheck(parent context.Context, fn CheckFunc, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				done <- fmt.Errorf("panic: %v", v)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if err := parent.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
@@ -0,0 +1,144 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/health"
+)
+
+func get(t *testing.T, mux *http.ServeMux, path string) (int, health.Report) {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var rep health.Report
+	if err := json.NewDecoder(rr.Body).Decode(&rep); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	return rr.Code, rep
+}
+
+func TestChecker(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	var dbDown atomic.Bool
+
+	c := health.New()
+	c.CacheTTL = -1
+	c.Register(
+		health.Check{Name: "deadlock", Liveness: true, Func: func(context.Context) error { return nil }},
+		health.Check{Name: "db", Func: func(context.Context) error {
+			if dbDown.Load() {
+				return dbErr
+			}
+			return nil
+		}},
+	)
+
+	mux := http.NewServeMux()
+	c.Mount(mux)
+
+	status, rep := get(t, mux, "/readyz")
+	if status != http.StatusOK || rep.Status != health.StatusOK || len(rep.Checks) != 2 {
+		t.Errorf("readyz: got %d %+v", status, rep)
+	}
+
+	dbDown.Store(true)
+	status, rep = get(t, mux, "/readyz")
+	if status != http.StatusServiceUnavailable || rep.Status != health.StatusFail {
+		t.Errorf("readyz with db down: got %d %+v", status, rep)
+	}
+	if got := rep.Checks["db"]; got.Status != health.StatusFail || got.Error != "" {
+		t.Errorf("db check: got %+v", got)
+	}
+
+	status, rep = get(t, mux, "/healthz")
+	if status != http.StatusOK || len(rep.Checks) != 1 || rep.Checks["deadlock"].Status != health.StatusOK {
+		t.Errorf("healthz should only run liveness checks: got %d %+v", status, rep)
+	}
+
+	dbDown.Store(false)
+	c.Shutdown()
+	status, rep = get(t, mux, "/readyz")
+	if status != http.StatusServiceUnavailable || rep.Status != health.StatusShuttingDown {
+		t.Errorf("readyz during shutdown: got %d %+v", status, rep)
+	}
+	if status, _ := get(t, mux, "/healthz"); status != http.StatusOK {
+		t.Errorf("healthz during shutdown: got %d", status)
+	}
+}
+
+func TestCheckerTimeoutAndPanic(t *testing.T) {
+	c := health.New()
+	c.ShowErrors = true
+	c.Register(
+		health.Check{Name: "slow", Timeout: 10 * time.Millisecond, Func: func(context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}},
+		health.Check{Name: "broken", Func: func(context.Context) error {
+			panic("boom")
+		}},
+	)
+
+	start := time.Now()
+	rep := c.Readiness(context.Background())
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("checks took %s, the timeout was not enforced", elapsed)
+	}
+
+	if got := rep.Checks["slow"]; got.Status != health.StatusFail || got.Error != "timed out after 10ms" {
+		t.Errorf("slow check: got %+v", got)
+	}
+	if got := rep.Checks["broken"]; got.Status != health.StatusFail || got.Error != "panic: boom" {
+		t.Errorf("broken check: got %+v", got)
+	}
+}
+
+func TestCheckerZeroValue(t *testing.T) {
+	var c health.Checker
+	c.Register(health.Check{Name: "db", Func: func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return errors.New("no deadline")
+		}
+		return nil
+	}})
+
+	if rep := c.Readiness(context.Background()); rep.Status != health.StatusOK {
+		t.Errorf("got %+v, want the default timeout", rep)
+	}
+}
+
+func TestCheckerConcurrentAndCached(t *testing.T) {
+	var calls atomic.Int32
+	slow := func(context.Context) error {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}
+
+	c := health.New()
+	c.Register(
+		health.Check{Name: "a", Func: slow},
+		health.Check{Name: "b", Func: slow},
+		health.Check{Name: "c", Func: slow},
+	)
+
+	start := time.Now()
+	c.Readiness(context.Background())
+	if elapsed := time.Since(start); elapsed > 140*time.Millisecond {
+		t.Errorf("checks took %s, they should run concurrently", elapsed)
+	}
+
+	c.Readiness(context.Background())
+	if got := calls.Load(); got != 3 {
+		t.Errorf("got %d calls, want cached results", got)
+	}
+}
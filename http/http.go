@@ -1,8 +1,29 @@
 package http
 
 const (
-	HeaderContentType  = "Content-Type"
-	MimeJSON           = "application/json"
-	MimeHTMLUTF8       = "text/html; charset=utf-8"
-	MimeFormUrlEncoded = "application/x-www-form-urlencoded"
+	HeaderContentType        = "Content-Type"
+	HeaderContentDisposition = "Content-Disposition"
+	HeaderVary               = "Vary"
+	MimeJSON                 = "application/json"
+	MimeProblemJSON          = "application/problem+json"
+	MimeNDJSON               = "application/x-ndjson"
+	MimeHTMLUTF8             = "text/html; charset=utf-8"
+	MimeEventStream          = "text/event-stream"
+	MimeFormUrlEncoded       = "application/x-www-form-urlencoded"
+)
+
+// htmx request and response headers
+const (
+	HeaderHXRequest               = "HX-Request"
+	HeaderHXBoosted               = "HX-Boosted"
+	HeaderHXTarget                = "HX-Target"
+	HeaderHXTrigger               = "HX-Trigger"
+	HeaderHXRedirect              = "HX-Redirect"
+	HeaderHXRefresh               = "HX-Refresh"
+	HeaderHXPushURL               = "HX-Push-Url"
+	HeaderHXReplaceURL            = "HX-Replace-Url"
+	HeaderHXRetarget              = "HX-Retarget"
+	HeaderHXReswap                = "HX-Reswap"
+	HeaderHXLocation              = "HX-Location"
+	HeaderHXHistoryRestoreRequest = "HX-History-Restore-Request"
 )
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+// AccessLogOptions configures the AccessLog middleware.
+type AccessLogOptions struct {
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+
+	// SampleRate is the fraction of successful requests that are logged,
+	// between 0 and 1. Requests that fail with a 4xx or 5xx status are always
+	// logged. Defaults to 1.
+	SampleRate float64
+
+	// Level picks the log level for a status. Defaults to Error for 5xx,
+	// Warn for 4xx and Info otherwise.
+	Level func(status int) slog.Level
+
+	// SkipPaths are never logged, such as health checks.
+	SkipPaths []string
+
+	// RoutePattern returns the route pattern that matched the request.
+	// See MuxPattern.
+	RoutePattern func(r *http.Request) string
+}
+
+// AccessLog logs every completed request with its status, size and latency.
+// The ip is the host of r.RemoteAddr; install RealIP before AccessLog to log
+// the client behind trusted proxies.
+func AccessLog(opts AccessLogOptions) Middleware {
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		opts.SampleRate = 1
+	}
+	if opts.Level == nil {
+		opts.Level = levelForStatus
+	}
+
+	skip := make(map[string]bool, len(opts.SkipPaths))
+	for _, p := range opts.SkipPaths {
+		skip[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			sw := newStatusWriter(w)
+
+			next.ServeHTTP(sw, r)
+
+			if sw.status < http.StatusBadRequest && opts.SampleRate < 1 && rand.Float64() >= opts.SampleRate {
+				return
+			}
+
+			logger := opts.Logger
+			if logger == nil {
+				logger = slog.Default()
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", sw.status),
+				slog.Int64("bytes", sw.bytes),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("ip", remoteHost(r)),
+				slog.String("user_agent", r.UserAgent()),
+			}
+			if opts.RoutePattern != nil {
+				attrs = append(attrs, slog.String("route", opts.RoutePattern(r)))
+			}
+			// RequestID may run inside this middleware, in which case the id
+			// only shows up in the response header.
+			id := request.ID(r)
+			if id == "" {
+				id = w.Header().Get(HeaderRequestID)
+			}
+			if id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+
+			logger.LogAttrs(r.Context(), opts.Level(sw.status), "request completed", attrs...)
+		})
+	}
+}
+
+// MuxPattern returns a RoutePattern that looks up the pattern in mux.
+func MuxPattern(mux *http.ServeMux) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		return pattern
+	}
+}
+
+func levelForStatus(status int) slog.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return slog.LevelError
+	case status >= http.StatusBadRequest:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
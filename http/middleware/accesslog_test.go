@@ -0,0 +1,109 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	})
+
+	h := middleware.Chain{
+		middleware.AccessLog(middleware.AccessLogOptions{Logger: logger, RoutePattern: middleware.MuxPattern(mux)}),
+		middleware.RequestID,
+	}.Then(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set(middleware.HeaderRequestID, "req-123")
+	req.Header.Set("X-Forwarded-For", "203.0.113.66")
+	req.RemoteAddr = "192.0.2.10:5000"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("unmarshal log entry %q: %v", buf.String(), err)
+	}
+
+	want := map[string]any{
+		"level":      "WARN",
+		"msg":        "request completed",
+		"method":     "GET",
+		"path":       "/users/7",
+		"route":      "GET /users/{id}",
+		"status":     float64(http.StatusNotFound),
+		"bytes":      float64(len("missing")),
+		"ip":         "192.0.2.10",
+		"user_agent": "test-agent",
+		"request_id": "req-123",
+	}
+	for k, v := range want {
+		if entry[k] != v {
+			t.Errorf("got %s=%v, want %v", k, entry[k], v)
+		}
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Errorf("entry should contain the duration")
+	}
+}
+
+func TestAccessLogSkipAndSample(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	status := http.StatusOK
+	h := middleware.AccessLog(middleware.AccessLogOptions{
+		Logger:     logger,
+		SampleRate: 0.0000001,
+		SkipPaths:  []string{"/healthz"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sampled", nil))
+	if buf.Len() != 0 {
+		t.Errorf("skipped and sampled out requests should not be logged, got %q", buf.String())
+	}
+
+	status = http.StatusInternalServerError
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/broken", nil))
+	if !strings.Contains(buf.String(), "level=ERROR") || !strings.Contains(buf.String(), "status=500") {
+		t.Errorf("server errors should always be logged at error level, got %q", buf.String())
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var got string
+	h := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = request.ID(r)
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(got) != 32 || rr.Header().Get(middleware.HeaderRequestID) != got {
+		t.Errorf("got id %q, header %q", got, rr.Header().Get(middleware.HeaderRequestID))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.HeaderRequestID, "bad id\n")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if got == "bad id\n" {
+		t.Errorf("malformed incoming ids should be replaced")
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+// BodyLimit rejects request bodies larger than limit bytes.
+// Bodies with a known length are rejected up front; others fail while being
+// read with an *http.MaxBytesError, which request.JSON reports as a 413 problem.
+func BodyLimit(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				response.WriteProblem(w, response.NewProblem(http.StatusRequestEntityTooLarge,
+					fmt.Sprintf("Request body must not be larger than %d bytes.", limit)))
+				return
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
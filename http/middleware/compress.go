@@ -0,0 +1,359 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+
+	defaultMinSize = 1024
+)
+
+// Content types that are already compressed and gain nothing from gzip
+var incompressibleTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-7z-compressed",
+	"application/x-rar-compressed",
+	"application/pdf",
+	"application/octet-stream",
+	"application/wasm",
+}
+
+// CompressOptions configures the Compress middleware.
+type CompressOptions struct {
+	// Level is a compression level from flate.HuffmanOnly to
+	// flate.BestCompression. Zero selects flate.DefaultCompression, so
+	// flate.NoCompression cannot be chosen; leave Compress out instead.
+	Level int
+
+	// MinSize is the smallest body that gets compressed. Defaults to 1024 bytes.
+	// Flushed responses are compressed regardless of their size.
+	MinSize int
+
+	// SkipTypes are extra content type prefixes that are never compressed.
+	SkipTypes []string
+}
+
+// Compress compresses responses with gzip or deflate, as negotiated with Accept-Encoding.
+// It panics when Level is invalid.
+func Compress(opts CompressOptions) Middleware {
+	if opts.Level == 0 {
+		opts.Level = flate.DefaultCompression
+	}
+	if _, err := gzip.NewWriterLevel(io.Discard, opts.Level); err != nil {
+		panic(fmt.Sprintf("middleware: invalid compression level %d", opts.Level))
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = defaultMinSize
+	}
+
+	skipTypes := append(append([]string{}, incompressibleTypes...), opts.SkipTypes...)
+	pools := newCompressorPools(opts.Level)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(ghttp.HeaderVary, "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        opts.MinSize,
+				skipTypes:      skipTypes,
+				pools:          pools,
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// honoring q-values. A * entry only applies to codings that are not listed
+// explicitly. It returns "" when neither is acceptable.
+func negotiateEncoding(header string) string {
+	weights := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		weights[name] = q
+	}
+
+	var best string
+	var bestQ float64
+
+	// Prefer gzip over deflate on equal weights.
+	for _, name := range []string{encodingGzip, encodingDeflate} {
+		q, ok := weights[name]
+		if !ok {
+			q = weights["*"]
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+
+	return best
+}
+
+// compressorPools reuses gzip and flate writers across responses.
+type compressorPools struct {
+	gzip  sync.Pool
+	flate sync.Pool
+}
+
+func newCompressorPools(level int) *compressorPools {
+	p := &compressorPools{}
+	p.gzip.New = func() any {
+		w, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			panic(fmt.Sprintf("invalid gzip level %d: %v", level, err))
+		}
+		return w
+	}
+	p.flate.New = func() any {
+		w, err := flate.NewWriter(io.Discard, level)
+		if err != nil {
+			panic(fmt.Sprintf("invalid flate level %d: %v", level, err))
+		}
+		return w
+	}
+	return p
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+func (p *compressorPools) get(encoding string, w io.Writer) compressor {
+	var c compressor
+	if encoding == encodingGzip {
+		c = p.gzip.Get().(*gzip.Writer)
+	} else {
+		c = p.flate.Get().(*flate.Writer)
+	}
+	c.Reset(w)
+	return c
+}
+
+func (p *compressorPools) put(encoding string, c compressor) {
+	if encoding == encodingGzip {
+		p.gzip.Put(c)
+	} else {
+		p.flate.Put(c)
+	}
+}
+
+// compressWriter buffers the start of a response until it can decide
+// whether compressing it is worthwhile.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding  string
+	minSize   int
+	skipTypes []string
+	pools     *compressorPools
+
+	status     int
+	buf        []byte
+	decided    bool
+	compressor compressor
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 {
+		return
+	}
+
+	// Informational responses are sent as they are.
+	if status >= 100 && status < 200 && status != http.StatusSwitchingProtocols {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	cw.status = status
+	if !cw.compressible() {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if !cw.decided {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < cw.minSize {
+			return len(b), nil
+		}
+		if err := cw.start(); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if cw.compressor != nil {
+		return cw.compressor.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush starts the response early, compressing it if its type allows.
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		if err := cw.start(); err != nil {
+			return
+		}
+	}
+	if cw.compressor != nil {
+		if err := cw.compressor.Flush(); err != nil {
+			return
+		}
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Hijack hands over the connection; nothing more is compressed.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	cw.decided = true
+	return http.NewResponseController(cw.ResponseWriter).Hijack()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// start sniffs the content type if needed and commits to compressing or not.
+func (cw *compressWriter) start() error {
+	h := cw.Header()
+	if h.Get(ghttp.HeaderContentType) == "" && len(cw.buf) > 0 {
+		h.Set(ghttp.HeaderContentType, http.DetectContentType(cw.buf))
+	}
+
+	cw.decide(cw.compressible())
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+
+	var err error
+	if cw.compressor != nil {
+		_, err = cw.compressor.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// decide writes the header, switching to the compressor when compress is set.
+func (cw *compressWriter) decide(compress bool) {
+	cw.decided = true
+
+	if compress {
+		h := cw.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		cw.compressor = cw.pools.get(cw.encoding, cw.ResponseWriter)
+	}
+
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+// compressible reports whether the response can be compressed at all.
+func (cw *compressWriter) compressible() bool {
+	h := cw.Header()
+
+	switch cw.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent, http.StatusSwitchingProtocols:
+		return false
+	}
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+
+	contentType := strings.ToLower(h.Get(ghttp.HeaderContentType))
+	if contentType == "" {
+		// Decided after sniffing the body.
+		return true
+	}
+	for _, prefix := range cw.skipTypes {
+		if strings.HasPrefix(contentType, prefix) && !strings.HasPrefix(contentType, "image/svg") {
+			return false
+		}
+	}
+	return true
+}
+
+// close sends any small buffered body uncompressed and releases the compressor.
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if cw.status == 0 {
+			// Nothing was written by the handler.
+			return
+		}
+
+		h := cw.Header()
+		if h.Get(ghttp.HeaderContentType) == "" && len(cw.buf) > 0 {
+			h.Set(ghttp.HeaderContentType, http.DetectContentType(cw.buf))
+		}
+		cw.decide(false)
+		if _, err := cw.ResponseWriter.Write(cw.buf); err != nil {
+			slog.Debug("write response", "reason", err)
+		}
+		cw.buf = nil
+	}
+
+	if cw.compressor != nil {
+		if err := cw.compressor.Close(); err != nil {
+			slog.Debug("close compressor", "reason", err)
+		}
+		cw.compressor.Reset(io.Discard)
+		cw.pools.put(cw.encoding, cw.compressor)
+		cw.compressor = nil
+	}
+}
@@ -0,0 +1,225 @@
+package middleware_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+var largeBody = strings.Repeat("gopherkit ", 500)
+
+func serveCompressed(t *testing.T, h http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rr := httptest.NewRecorder()
+
+	middleware.Compress(middleware.CompressOptions{})(h).ServeHTTP(rr, req)
+	return rr
+}
+
+func decompress(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	var r io.Reader
+	switch rr.Header().Get("Content-Encoding") {
+	case "gzip":
+		zr, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		r = zr
+	case "deflate":
+		r = flate.NewReader(rr.Body)
+	default:
+		r = rr.Body
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	return string(b)
+}
+
+func TestCompressNegotiation(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ghttp.HeaderContentType, "text/plain")
+		_, _ = io.WriteString(w, largeBody)
+	})
+
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate;q=0.8", "deflate"},
+		{"gzip;q=0", ""},
+		{"br", ""},
+		{"*", "gzip"},
+		{"gzip;q=0, *", "deflate"},
+		{"gzip;q=0, deflate;q=0, *", ""},
+		{"deflate;q=0.5, *;q=0.2", "deflate"},
+		{"*;q=0", ""},
+	}
+
+	for _, tt := range tests {
+		rr := serveCompressed(t, h, tt.acceptEncoding)
+
+		if got := rr.Header().Get("Content-Encoding"); got != tt.want {
+			t.Errorf("%q: got encoding %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+		if got := decompress(t, rr); got != largeBody {
+			t.Errorf("%q: body did not round trip", tt.acceptEncoding)
+		}
+		if vary := rr.Header().Get(ghttp.HeaderVary); vary != "Accept-Encoding" {
+			t.Errorf("%q: got Vary %q", tt.acceptEncoding, vary)
+		}
+	}
+}
+
+func TestCompressSkips(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"small body", func(w http.ResponseWriter, r *http.Request) {
+			response.JSON(w, http.StatusOK, map[string]string{"ok": "yes"})
+		}},
+		{"compressed type", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(ghttp.HeaderContentType, "image/png")
+			_, _ = io.WriteString(w, largeBody)
+		}},
+		{"already encoded", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = io.WriteString(w, largeBody)
+		}},
+		{"no content", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := serveCompressed(t, tt.handler, "gzip")
+
+			if enc := rr.Header().Get("Content-Encoding"); enc == "gzip" {
+				t.Errorf("response should not be compressed")
+			}
+		})
+	}
+}
+
+func TestCompressJSON(t *testing.T) {
+	items := make([]string, 200)
+	for i := range items {
+		items[i] = "gopher"
+	}
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusCreated, items)
+	})
+
+	rr := serveCompressed(t, h, "gzip")
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusCreated)
+	}
+	if rr.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("JSON response should be compressed")
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeJSON {
+		t.Errorf("got content type %q", ct)
+	}
+	if !strings.HasPrefix(decompress(t, rr), `["gopher","gopher"`) {
+		t.Errorf("unexpected body")
+	}
+}
+
+func TestCompressSniffsContentType(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "<!DOCTYPE html><html>"+largeBody)
+	})
+
+	rr := serveCompressed(t, h, "gzip")
+
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeHTMLUTF8 {
+		t.Errorf("content type should be sniffed from the uncompressed body, got %q", ct)
+	}
+}
+
+func TestCompressStreaming(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seq := func(yield func(int) bool) {
+			for i := 0; i < 3; i++ {
+				if !yield(i) {
+					return
+				}
+			}
+		}
+		response.JSONStream(w, r, http.StatusOK, seq, response.StreamOptions{Format: response.StreamNDJSON, FlushEvery: 1})
+	})
+
+	rr := serveCompressed(t, h, "gzip")
+
+	if rr.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("flushed stream should be compressed")
+	}
+	if !rr.Flushed {
+		t.Errorf("flushes should reach the underlying writer")
+	}
+	if got := decompress(t, rr); got != "0\n1\n2\n" {
+		t.Errorf("got body %q", got)
+	}
+}
+
+func TestCompressHijack(t *testing.T) {
+	srv := httptest.NewServer(middleware.Compress(middleware.CompressOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		_ = buf.Flush()
+	})))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "ok" {
+		t.Errorf("got body %q", body)
+	}
+}
+
+func TestCompressInvalidLevel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Compress with an invalid level did not panic")
+		}
+	}()
+
+	middleware.Compress(middleware.CompressOptions{Level: 42})
+}
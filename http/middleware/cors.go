@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+// CORS headers
+const (
+	HeaderOrigin                        = "Origin"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	HeaderAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	HeaderAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	HeaderAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	defaultCORSHeaders = []string{ghttp.HeaderContentType, "Authorization", "X-Requested-With"}
+)
+
+// CORSOptions configures the CORS middleware.
+type CORSOptions struct {
+	// AllowedOrigins are exact origins such as https://app.example.com,
+	// wildcard origins such as https://*.example.com, or * for any origin.
+	AllowedOrigins []string
+
+	// AllowedMethods defaults to GET, HEAD, POST, PUT, PATCH and DELETE.
+	AllowedMethods []string
+
+	// AllowedHeaders defaults to Content-Type, Authorization and
+	// X-Requested-With. Use * to allow any header.
+	AllowedHeaders []string
+
+	// ExposedHeaders are response headers that scripts may read.
+	ExposedHeaders []string
+
+	// AllowCredentials lets browsers send cookies and authorization headers.
+	// It cannot be combined with the * origin.
+	AllowCredentials bool
+
+	// MaxAge is how long browsers may cache preflight results.
+	MaxAge time.Duration
+}
+
+// CORS answers preflight requests and adds CORS headers to allowed cross-origin requests.
+// It panics when AllowCredentials is combined with the * origin, since that
+// would let any site make authenticated requests.
+func CORS(opts CORSOptions) Middleware {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = defaultCORSMethods
+	}
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = defaultCORSHeaders
+	}
+
+	c := &cors{
+		opts:           opts,
+		allowedMethods: strings.Join(opts.AllowedMethods, ", "),
+		exposedHeaders: strings.Join(opts.ExposedHeaders, ", "),
+		headers:        make(map[string]bool, len(opts.AllowedHeaders)),
+	}
+	for _, origin := range opts.AllowedOrigins {
+		if origin == "*" {
+			c.anyOrigin = true
+		}
+	}
+	if c.anyOrigin && opts.AllowCredentials {
+		panic("middleware: CORS cannot allow credentials for any origin")
+	}
+	for _, h := range opts.AllowedHeaders {
+		if h == "*" {
+			c.anyHeader = true
+		}
+		c.headers[http.CanonicalHeaderKey(h)] = true
+	}
+
+	return c.handler
+}
+
+type cors struct {
+	opts           CORSOptions
+	allowedMethods string
+	exposedHeaders string
+	headers        map[string]bool
+	anyOrigin      bool
+	anyHeader      bool
+}
+
+func (c *cors) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		origin := r.Header.Get(HeaderOrigin)
+		preflight := r.Method == http.MethodOptions && r.Header.Get(HeaderAccessControlRequestMethod) != ""
+
+		if preflight {
+			h.Add(ghttp.HeaderVary, HeaderOrigin)
+			h.Add(ghttp.HeaderVary, HeaderAccessControlRequestMethod)
+			h.Add(ghttp.HeaderVary, HeaderAccessControlRequestHeaders)
+			c.preflight(w, r, origin)
+			return
+		}
+
+		h.Add(ghttp.HeaderVary, HeaderOrigin)
+		if origin != "" && c.originAllowed(origin) {
+			c.setOrigin(h, origin)
+			if c.exposedHeaders != "" {
+				h.Set(HeaderAccessControlExposeHeaders, c.exposedHeaders)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// preflight answers an OPTIONS preflight without calling the next handler.
+func (c *cors) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	if origin == "" || !c.originAllowed(origin) || !c.methodAllowed(r.Header.Get(HeaderAccessControlRequestMethod)) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	requested := parseHeaderList(r.Header.Get(HeaderAccessControlRequestHeaders))
+	if !c.headersAllowed(requested) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	h := w.Header()
+	c.setOrigin(h, origin)
+	h.Set(HeaderAccessControlAllowMethods, c.allowedMethods)
+	if len(requested) > 0 {
+		h.Set(HeaderAccessControlAllowHeaders, strings.Join(requested, ", "))
+	}
+	if c.opts.MaxAge > 0 {
+		h.Set(HeaderAccessControlMaxAge, strconv.Itoa(int(c.opts.MaxAge.Seconds())))
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *cors) setOrigin(h http.Header, origin string) {
+	if c.anyOrigin {
+		h.Set(HeaderAccessControlAllowOrigin, "*")
+	} else {
+		h.Set(HeaderAccessControlAllowOrigin, origin)
+	}
+	if c.opts.AllowCredentials {
+		h.Set(HeaderAccessControlAllowCredentials, "true")
+	}
+}
+
+func (c *cors) originAllowed(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+	for _, allowed := range c.opts.AllowedOrigins {
+		if matchOrigin(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cors) methodAllowed(method string) bool {
+	for _, m := range c.opts.AllowedMethods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *cors) headersAllowed(requested []string) bool {
+	if c.anyHeader {
+		return true
+	}
+	for _, h := range requested {
+		if !c.headers[http.CanonicalHeaderKey(h)] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchOrigin compares an origin with an exact or single-wildcard pattern.
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return strings.EqualFold(pattern, origin)
+	}
+
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+
+	return len(origin) > len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) &&
+		strings.HasSuffix(origin, suffix)
+}
+
+func parseHeaderList(v string) []string {
+	var headers []string
+	for _, h := range strings.Split(v, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			headers = append(headers, strings.ToLower(h))
+		}
+	}
+	return headers
+}
@@ -0,0 +1,170 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+)
+
+func serveCORS(opts middleware.CORSOptions, r *http.Request) (*httptest.ResponseRecorder, bool) {
+	var called bool
+	h := middleware.CORS(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, r)
+	return rr, called
+}
+
+func TestCORSOrigins(t *testing.T) {
+	tests := []struct {
+		name        string
+		origins     []string
+		credentials bool
+		origin      string
+		want        string
+	}{
+		{"exact", []string{"https://app.example.com"}, false, "https://app.example.com", "https://app.example.com"},
+		{"exact mismatch", []string{"https://app.example.com"}, false, "https://evil.com", ""},
+		{"wildcard subdomain", []string{"https://*.example.com"}, false, "https://api.example.com", "https://api.example.com"},
+		{"wildcard apex", []string{"https://*.example.com"}, false, "https://example.com", ""},
+		{"wildcard suffix attack", []string{"https://*.example.com"}, false, "https://example.com.evil.com", ""},
+		{"any", []string{"*"}, false, "https://anything.dev", "*"},
+		{"exact with credentials", []string{"https://app.example.com"}, true, "https://app.example.com", "https://app.example.com"},
+		{"no origin", []string{"*"}, false, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.origin != "" {
+				req.Header.Set(middleware.HeaderOrigin, tt.origin)
+			}
+
+			rr, called := serveCORS(middleware.CORSOptions{
+				AllowedOrigins:   tt.origins,
+				AllowCredentials: tt.credentials,
+				ExposedHeaders:   []string{"X-Total-Count"},
+			}, req)
+
+			if !called {
+				t.Fatal("handler was not called")
+			}
+			if got := rr.Header().Get(middleware.HeaderAccessControlAllowOrigin); got != tt.want {
+				t.Errorf("got Allow-Origin %q, want %q", got, tt.want)
+			}
+			if got := rr.Header().Get("Vary"); got != middleware.HeaderOrigin {
+				t.Errorf("got Vary %q", got)
+			}
+
+			wantExpose := ""
+			if tt.want != "" {
+				wantExpose = "X-Total-Count"
+			}
+			if got := rr.Header().Get(middleware.HeaderAccessControlExposeHeaders); got != wantExpose {
+				t.Errorf("got Expose-Headers %q, want %q", got, wantExpose)
+			}
+
+			wantCredentials := ""
+			if tt.credentials && tt.want != "" {
+				wantCredentials = "true"
+			}
+			if got := rr.Header().Get(middleware.HeaderAccessControlAllowCredentials); got != wantCredentials {
+				t.Errorf("got Allow-Credentials %q, want %q", got, wantCredentials)
+			}
+		})
+	}
+}
+
+func TestCORSAnyOriginWithCredentials(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("CORS with credentials for any origin did not panic")
+		}
+	}()
+
+	middleware.CORS(middleware.CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+}
+
+func TestCORSPreflight(t *testing.T) {
+	opts := middleware.CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPut},
+		AllowedHeaders: []string{"Content-Type", "X-Custom"},
+		MaxAge:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		status  int
+	}{
+		{"allowed", "https://app.example.com", http.MethodPut, "content-type, x-custom", http.StatusNoContent},
+		{"no headers", "https://app.example.com", http.MethodGet, "", http.StatusNoContent},
+		{"origin", "https://evil.com", http.MethodPut, "", http.StatusForbidden},
+		{"method", "https://app.example.com", http.MethodDelete, "", http.StatusForbidden},
+		{"header", "https://app.example.com", http.MethodPut, "authorization", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/", nil)
+			req.Header.Set(middleware.HeaderOrigin, tt.origin)
+			req.Header.Set(middleware.HeaderAccessControlRequestMethod, tt.method)
+			if tt.headers != "" {
+				req.Header.Set(middleware.HeaderAccessControlRequestHeaders, tt.headers)
+			}
+
+			rr, called := serveCORS(opts, req)
+
+			if called {
+				t.Error("preflight reached the handler")
+			}
+			if rr.Code != tt.status {
+				t.Fatalf("got status %d, want %d", rr.Code, tt.status)
+			}
+
+			vary := rr.Header().Values("Vary")
+			if len(vary) != 3 {
+				t.Errorf("got Vary %v", vary)
+			}
+
+			if tt.status != http.StatusNoContent {
+				if got := rr.Header().Get(middleware.HeaderAccessControlAllowOrigin); got != "" {
+					t.Errorf("rejected preflight got Allow-Origin %q", got)
+				}
+				return
+			}
+
+			h := rr.Header()
+			if got := h.Get(middleware.HeaderAccessControlAllowOrigin); got != tt.origin {
+				t.Errorf("got Allow-Origin %q", got)
+			}
+			if got := h.Get(middleware.HeaderAccessControlAllowMethods); got != "GET, PUT" {
+				t.Errorf("got Allow-Methods %q", got)
+			}
+			if got := h.Get(middleware.HeaderAccessControlAllowHeaders); got != tt.headers {
+				t.Errorf("got Allow-Headers %q, want %q", got, tt.headers)
+			}
+			if got := h.Get(middleware.HeaderAccessControlMaxAge); got != "600" {
+				t.Errorf("got Max-Age %q", got)
+			}
+		})
+	}
+}
+
+func TestCORSPlainOptions(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set(middleware.HeaderOrigin, "https://app.example.com")
+
+	_, called := serveCORS(middleware.CORSOptions{AllowedOrigins: []string{"*"}}, req)
+	if !called {
+		t.Error("OPTIONS without a preflight should reach the handler")
+	}
+}
@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/response"
+	"github.com/ferdiebergado/gopherkit/signer"
+)
+
+const (
+	HeaderCSRFToken    = "X-CSRF-Token"
+	HeaderSecFetchSite = "Sec-Fetch-Site"
+
+	defaultCSRFCookie = "csrf"
+	defaultCSRFField  = "csrf_token"
+
+	csrfTokenSize = 32
+)
+
+type contextKey string
+
+const csrfTokenKey contextKey = "csrfToken"
+
+// CSRF protects unsafe requests with signed double-submit tokens.
+//
+// A random token is kept in a signed cookie. Unsafe requests must send it back
+// in the X-CSRF-Token header or the csrf_token form field, masked so that it
+// differs on every page. Requests are also rejected when Sec-Fetch-Site or
+// Origin show that they come from another site.
+type CSRF struct {
+	signer *signer.Signer
+
+	// CookieName defaults to csrf.
+	CookieName string
+
+	// HeaderName defaults to X-CSRF-Token.
+	HeaderName string
+
+	// FieldName is the form field holding the token. Defaults to csrf_token.
+	FieldName string
+
+	// Secure restricts the cookie to HTTPS.
+	Secure bool
+
+	// TrustedOrigins are other origins, such as https://admin.example.com,
+	// allowed to send unsafe requests.
+	TrustedOrigins []string
+
+	// ErrorHandler handles rejected requests.
+	// Defaults to a 403 Forbidden problem.
+	ErrorHandler http.Handler
+}
+
+// Creates a CSRF that signs its cookie with key
+func NewCSRF(key []byte) *CSRF {
+	return &CSRF{
+		signer:     signer.New(key, "csrf"),
+		CookieName: defaultCSRFCookie,
+		HeaderName: HeaderCSRFToken,
+		FieldName:  defaultCSRFField,
+		Secure:     true,
+	}
+}
+
+// Middleware issues the token cookie and verifies unsafe requests.
+// Safe methods are never checked.
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(ghttp.HeaderVary, "Cookie")
+
+		token, ok := c.cookieToken(r)
+		if !ok {
+			token = newCSRFToken()
+			http.SetCookie(w, c.cookie(token))
+		}
+		r = r.WithContext(context.WithValue(r.Context(), csrfTokenKey, token))
+
+		if isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if reason := c.verify(r, token); reason != "" {
+			slog.Warn("csrf check failed", "reason", reason, "method", r.Method, "path", r.URL.Path)
+			c.reject(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Token returns a masked token for the request, to be sent back with unsafe
+// requests. Every call returns a different value for the same token.
+// It returns "" when the request did not pass through the middleware.
+func (c *CSRF) Token(r *http.Request) string {
+	token, _ := r.Context().Value(csrfTokenKey).([]byte)
+	if token == nil {
+		return ""
+	}
+	return maskToken(token)
+}
+
+// TemplateFuncs exposes the token to templates as csrfToken and as a hidden
+// input named after FieldName as csrfField, for use as RendererConfig.RequestFuncs:
+//
+//	<form method="post">{{csrfField}}...</form>
+func (c *CSRF) TemplateFuncs(_ http.ResponseWriter, r *http.Request) template.FuncMap {
+	token := c.Token(r)
+	return template.FuncMap{
+		"csrfToken": func() string {
+			return token
+		},
+		"csrfField": func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(c.FieldName) +
+				`" value="` + template.HTMLEscapeString(token) + `">`)
+		},
+	}
+}
+
+// verify returns why the request failed the check, or "" if it passed.
+func (c *CSRF) verify(r *http.Request, token []byte) string {
+	origin := r.Header.Get(HeaderOrigin)
+	trusted := origin != "" && c.trustedOrigin(origin)
+
+	if r.Header.Get(HeaderSecFetchSite) == "cross-site" && !trusted {
+		return "cross-site request"
+	}
+	if origin != "" && !trusted && !sameOrigin(r, origin) {
+		return "origin mismatch"
+	}
+
+	sent := r.Header.Get(c.HeaderName)
+	if sent == "" {
+		// Works for both urlencoded and multipart forms.
+		sent = r.PostFormValue(c.FieldName)
+	}
+	if sent == "" {
+		return "missing token"
+	}
+
+	unmasked, ok := unmaskToken(sent)
+	if !ok || subtle.ConstantTimeCompare(unmasked, token) != 1 {
+		return "invalid token"
+	}
+	return ""
+}
+
+func (c *CSRF) reject(w http.ResponseWriter, r *http.Request) {
+	if c.ErrorHandler != nil {
+		c.ErrorHandler.ServeHTTP(w, r)
+		return
+	}
+	response.Forbidden(w, "The request could not be verified. Reload the page and try again.")
+}
+
+func (c *CSRF) trustedOrigin(origin string) bool {
+	for _, o := range c.TrustedOrigins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// cookieToken returns the token stored in a valid cookie.
+func (c *CSRF) cookieToken(r *http.Request) ([]byte, bool) {
+	cookie, err := r.Cookie(c.CookieName)
+	if err != nil {
+		return nil, false
+	}
+
+	token, err := c.signer.Verify(cookie.Value)
+	if err != nil || len(token) != csrfTokenSize {
+		return nil, false
+	}
+	return token, true
+}
+
+func (c *CSRF) cookie(token []byte) *http.Cookie {
+	return &http.Cookie{
+		Name:     c.CookieName,
+		Value:    c.signer.Sign(token),
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// sameOrigin reports whether origin names the host the request was sent to.
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func newCSRFToken() []byte {
+	b := make([]byte, csrfTokenSize)
+	_, _ = rand.Read(b)
+	return b
+}
+
+// maskToken XORs the token with a one-time pad that is sent along with it,
+// so the token never appears twice in a response body (BREACH).
+func maskToken(token []byte) string {
+	b := make([]byte, 2*len(token))
+	pad := b[:len(token)]
+	_, _ = rand.Read(pad)
+	for i := range token {
+		b[len(token)+i] = pad[i] ^ token[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func unmaskToken(masked string) ([]byte, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(masked)
+	if err != nil || len(b) != 2*csrfTokenSize {
+		return nil, false
+	}
+
+	token := make([]byte, csrfTokenSize)
+	for i := range token {
+		token[i] = b[i] ^ b[csrfTokenSize+i]
+	}
+	return token, true
+}
@@ -0,0 +1,156 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+var csrfKey = []byte("0123456789abcdef0123456789abcdef")
+
+// csrfForm renders a form page and returns its cookie and masked token.
+func csrfForm(t *testing.T, h http.Handler) (*http.Cookie, string) {
+	t.Helper()
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "http://example.com/form", nil))
+
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+
+	m := regexp.MustCompile(`<input type="hidden" name="csrf_token" value="([^"]+)">`).FindStringSubmatch(rr.Body.String())
+	if m == nil {
+		t.Fatalf("no token field in %q", rr.Body.String())
+	}
+	return cookies[0], m[1]
+}
+
+func newCSRFHandler(t *testing.T, csrf *middleware.CSRF) http.Handler {
+	t.Helper()
+
+	rd, err := response.NewRenderer(response.RendererConfig{
+		FS: fstest.MapFS{
+			"layout.html":     {Data: []byte(`{{template "content" .}}`)},
+			"pages/form.html": {Data: []byte(`{{define "content"}}<form method="post">{{csrfField}}</form>{{end}}`)},
+		},
+		RequestFuncs: []response.RequestFuncs{csrf.TemplateFuncs},
+	})
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+
+	return csrf.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			rd.Render(w, r, http.StatusOK, "form", nil)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestCSRF(t *testing.T) {
+	csrf := middleware.NewCSRF(csrfKey)
+	csrf.TrustedOrigins = []string{"https://admin.example.com"}
+	h := newCSRFHandler(t, csrf)
+
+	cookie, token := csrfForm(t, h)
+	_, other := csrfForm(t, h)
+
+	tests := []struct {
+		name    string
+		cookie  bool
+		field   string
+		header  string
+		headers map[string]string
+		status  int
+	}{
+		{"form field", true, token, "", nil, http.StatusNoContent},
+		{"header", true, "", token, nil, http.StatusNoContent},
+		{"same origin", true, token, "", map[string]string{"Origin": "http://example.com", "Sec-Fetch-Site": "same-origin"}, http.StatusNoContent},
+		{"trusted origin", true, token, "", map[string]string{"Origin": "https://admin.example.com", "Sec-Fetch-Site": "cross-site"}, http.StatusNoContent},
+		{"missing token", true, "", "", nil, http.StatusForbidden},
+		{"missing cookie", false, token, "", nil, http.StatusForbidden},
+		{"token of another cookie", true, other, "", nil, http.StatusForbidden},
+		{"garbage token", true, "not-a-token", "", nil, http.StatusForbidden},
+		{"cross site", true, token, "", map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"foreign origin", true, token, "", map[string]string{"Origin": "https://evil.com"}, http.StatusForbidden},
+		{"null origin", true, token, "", map[string]string{"Origin": "null"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.field != "" {
+				form.Set("csrf_token", tt.field)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/form", strings.NewReader(form.Encode()))
+			req.Header.Set(ghttp.HeaderContentType, ghttp.MimeFormUrlEncoded)
+			if tt.cookie {
+				req.AddCookie(cookie)
+			}
+			if tt.header != "" {
+				req.Header.Set(middleware.HeaderCSRFToken, tt.header)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("got status %d, want %d", rr.Code, tt.status)
+			}
+			if tt.status == http.StatusForbidden && rr.Header().Get(ghttp.HeaderContentType) != ghttp.MimeProblemJSON {
+				t.Errorf("got content type %q", rr.Header().Get(ghttp.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestCSRFMasksToken(t *testing.T) {
+	csrf := middleware.NewCSRF(csrfKey)
+	h := newCSRFHandler(t, csrf)
+	cookie, _ := csrfForm(t, h)
+
+	var tokens []string
+	csrf.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, csrf.Token(r), csrf.Token(r))
+	})).ServeHTTP(httptest.NewRecorder(), func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(cookie)
+		return req
+	}())
+
+	if tokens[0] == tokens[1] {
+		t.Error("masked tokens should differ")
+	}
+	if got := csrf.Token(httptest.NewRequest(http.MethodGet, "/", nil)); got != "" {
+		t.Errorf("got token %q outside the middleware", got)
+	}
+}
+
+func TestCSRFErrorHandler(t *testing.T) {
+	csrf := middleware.NewCSRF(csrfKey)
+	csrf.ErrorHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	rr := httptest.NewRecorder()
+	newCSRFHandler(t, csrf).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/", nil))
+
+	if rr.Code != http.StatusTeapot {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusTeapot)
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+const (
+	HeaderMethodOverride = "X-HTTP-Method-Override"
+	FieldMethodOverride  = "_method"
+)
+
+// MethodOverride lets HTML forms, which can only POST, send PUT, PATCH and
+// DELETE requests through a _method form field or the X-HTTP-Method-Override
+// header. It must wrap the router, since routing depends on the method.
+func MethodOverride(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			method := r.Header.Get(HeaderMethodOverride)
+			if method == "" && isForm(r) {
+				method = r.PostFormValue(FieldMethodOverride)
+			}
+
+			switch method = strings.ToUpper(method); method {
+			case http.MethodPut, http.MethodPatch, http.MethodDelete:
+				r.Method = method
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isForm(r *http.Request) bool {
+	ct := r.Header.Get(ghttp.HeaderContentType)
+	return strings.HasPrefix(ct, ghttp.MimeFormUrlEncoded) || strings.HasPrefix(ct, "multipart/form-data")
+}
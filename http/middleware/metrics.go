@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/metrics"
+)
+
+// MetricsOptions configures the Metrics middleware.
+type MetricsOptions struct {
+	// Namespace prefixes the metric names. Defaults to http.
+	Namespace string
+
+	// Buckets of the latency histogram, in seconds. Defaults to metrics.DefaultBuckets.
+	Buckets []float64
+
+	// RoutePattern returns the route pattern that matched the request.
+	// See MuxPattern. Without it every request shares an empty route label;
+	// raw paths must never be used, as every distinct path creates new series.
+	RoutePattern func(r *http.Request) string
+}
+
+// Metrics registers request metrics with reg and records them for every request:
+//
+//	<namespace>_requests_total{method, route, status}
+//	<namespace>_request_duration_seconds{method, route, status}
+//	<namespace>_requests_in_flight{method, route}
+func Metrics(reg *metrics.Registry, opts MetricsOptions) Middleware {
+	if opts.Namespace == "" {
+		opts.Namespace = "http"
+	}
+
+	requests := reg.Counter(opts.Namespace+"_requests_total",
+		"Number of handled requests.", "method", "route", "status")
+	duration := reg.Histogram(opts.Namespace+"_request_duration_seconds",
+		"Time taken to handle requests.", opts.Buckets, "method", "route", "status")
+	inFlight := reg.Gauge(opts.Namespace+"_requests_in_flight",
+		"Number of requests being handled.", "method", "route")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var route string
+			if opts.RoutePattern != nil {
+				route = opts.RoutePattern(r)
+			}
+			method := methodLabel(r.Method)
+
+			inFlight.Inc(method, route)
+			defer inFlight.Dec(method, route)
+
+			start := time.Now()
+			sw := newStatusWriter(w)
+			next.ServeHTTP(sw, r)
+
+			status := strconv.Itoa(sw.status)
+			requests.Inc(method, route, status)
+			duration.Observe(time.Since(start).Seconds(), method, route, status)
+		})
+	}
+}
+
+// methodLabel folds nonstandard methods into one label value, since clients
+// can send any method.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
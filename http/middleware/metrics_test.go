@@ -0,0 +1,63 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	mux := http.NewServeMux()
+
+	var inFlight string
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var sb strings.Builder
+		_ = reg.WriteText(&sb)
+		inFlight = sb.String()
+	})
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	h := middleware.Metrics(reg, middleware.MetricsOptions{
+		RoutePattern: middleware.MuxPattern(mux),
+		Buckets:      []float64{1},
+	})(mux)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/users/1", nil),
+		httptest.NewRequest(http.MethodGet, "/users/2", nil),
+		httptest.NewRequest(http.MethodPost, "/users", nil),
+		httptest.NewRequest("PURGE", "/users", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if !strings.Contains(inFlight, `http_requests_in_flight{method="GET",route="GET /users/{id}"} 1`) {
+		t.Errorf("in-flight gauge not raised during the request:\n%s", inFlight)
+	}
+
+	var sb strings.Builder
+	if err := reg.WriteText(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="GET /users/{id}",status="200"} 2`,
+		`http_requests_total{method="POST",route="POST /users",status="201"} 1`,
+		`http_requests_total{method="OTHER",route="",status="405"} 1`,
+		`http_request_duration_seconds_bucket{method="GET",route="GET /users/{id}",status="200",le="1"} 2`,
+		`http_request_duration_seconds_count{method="POST",route="POST /users",status="201"} 1`,
+		`http_requests_in_flight{method="GET",route="GET /users/{id}"} 0`,
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Errorf("missing %s in\n%s", want, out)
+		}
+	}
+}
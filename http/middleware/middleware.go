@@ -0,0 +1,33 @@
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler with extra behavior.
+type Middleware func(http.Handler) http.Handler
+
+// Chain is a list of middleware. The first middleware is the outermost,
+// so it sees the request first and the response last.
+type Chain []Middleware
+
+// Append returns a new chain with mw added after the existing middleware.
+func (c Chain) Append(mw ...Middleware) Chain {
+	chain := make(Chain, 0, len(c)+len(mw))
+	chain = append(chain, c...)
+	return append(chain, mw...)
+}
+
+// Then wraps h with the middleware in the chain.
+func (c Chain) Then(h http.Handler) http.Handler {
+	if h == nil {
+		h = http.DefaultServeMux
+	}
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
+
+// ThenFunc wraps fn with the middleware in the chain.
+func (c Chain) ThenFunc(fn http.HandlerFunc) http.Handler {
+	return c.Then(fn)
+}
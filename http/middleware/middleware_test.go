@@ -0,0 +1,300 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+)
+
+func tag(name string, order *[]string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*order = append(*order, name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+
+	base := middleware.Chain{tag("a", &order), tag("b", &order)}
+	extended := base.Append(tag("c", &order))
+
+	h := extended.ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := "a,b,c,handler"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("got order %q, want %q", got, want)
+	}
+	if len(base) != 2 {
+		t.Errorf("append should not modify the original chain")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var buf bytes.Buffer
+	oldHandler := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(oldHandler)
+
+	h := middleware.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("kaboom")
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(buf.String(), "panic: kaboom") || !strings.Contains(buf.String(), "stack_trace=") {
+		t.Errorf("panic should be logged with a stack trace, got %q", buf.String())
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	var buf bytes.Buffer
+	oldHandler := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(oldHandler)
+
+	h := middleware.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = io.WriteString(w, "partial")
+		panic("kaboom")
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rr.Code != http.StatusAccepted || rr.Body.String() != "partial" {
+		t.Errorf("got %d %q, want the response left as written", rr.Code, rr.Body)
+	}
+	if !strings.Contains(buf.String(), "panic: kaboom") || !strings.Contains(buf.String(), "stack_trace=") {
+		t.Errorf("panic should be logged with a stack trace, got %q", buf.String())
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	h := middleware.Timeout(10 * time.Millisecond)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusServiceUnavailable)
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeProblemJSON {
+		t.Errorf("got content type %q", ct)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	h := middleware.BodyLimit(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		}
+	}))
+
+	tests := []struct {
+		name          string
+		body          string
+		unknownLength bool
+		want          int
+	}{
+		{"within limit", "small", false, http.StatusOK},
+		{"known length over limit", "this is too large", false, http.StatusRequestEntityTooLarge},
+		{"unknown length over limit", "this is too large", true, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.unknownLength {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+
+			h.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("got status %d, want %d", rr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"trusted proxy", "10.0.0.1:4000", "203.0.113.9", "", "203.0.113.9"},
+		{"untrusted peer", "198.51.100.7:4000", "203.0.113.9", "", "198.51.100.7"},
+		{"spoofed entry", "10.0.0.1:4000", "192.0.2.1, 203.0.113.9", "", "203.0.113.9"},
+		{"proxy chain", "10.0.0.1:4000", "203.0.113.9, 10.0.0.2", "", "203.0.113.9"},
+		{"only proxies", "10.0.0.1:4000", "10.0.0.3, 10.0.0.2", "", "10.0.0.3"},
+		{"invalid header", "10.0.0.1:4000", "not-an-ip", "", "10.0.0.1"},
+		{"real ip", "10.0.0.1:4000", "", "203.0.113.9", "203.0.113.9"},
+		{"untrusted real ip", "198.51.100.7:4000", "", "203.0.113.9", "198.51.100.7"},
+		{"no header", "10.0.0.1:4000", "", "", "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remoteAddr string
+			h := middleware.RealIP(proxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				remoteAddr = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			host, _, err := net.SplitHostPort(remoteAddr)
+			if err != nil || host != tt.want {
+				t.Errorf("got remote addr %q, want host %q", remoteAddr, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIPRequiresTrustedProxies(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RealIP without trusted proxies did not panic")
+		}
+	}()
+
+	middleware.RealIP(nil)
+}
+
+func TestTrailingSlash(t *testing.T) {
+	h := middleware.TrailingSlash(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		method, target string
+		status         int
+		location       string
+	}{
+		{http.MethodGet, "/users/?page=2", http.StatusMovedPermanently, "/users?page=2"},
+		{http.MethodPost, "/users//", http.StatusPermanentRedirect, "/users"},
+		{http.MethodGet, "/", http.StatusNoContent, ""},
+		{http.MethodGet, "/users", http.StatusNoContent, ""},
+		{http.MethodGet, "//evil.example/", http.StatusMovedPermanently, "/evil.example"},
+		{http.MethodGet, "/\\evil.example/", http.StatusMovedPermanently, "/%5Cevil.example"},
+		{http.MethodGet, "///", http.StatusMovedPermanently, "/"},
+		{http.MethodGet, "/a%3Fadmin=1/", http.StatusMovedPermanently, "/a%3Fadmin=1"},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if rr.Code != tt.status {
+			t.Errorf("%s %s: got status %d, want %d", tt.method, tt.target, rr.Code, tt.status)
+		}
+		if loc := rr.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s %s: got location %q, want %q", tt.method, tt.target, loc, tt.location)
+		}
+	}
+}
+
+func TestTrailingSlashKeepsRoutedSlashes(t *testing.T) {
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	mux.HandleFunc("GET /static/{path...}", ok)
+	mux.HandleFunc("GET /docs/", ok)
+	mux.HandleFunc("GET /users", ok)
+	mux.HandleFunc("/", ok)
+
+	h := middleware.TrailingSlash(middleware.MuxPattern(mux))(mux)
+
+	tests := []struct {
+		target   string
+		status   int
+		location string
+	}{
+		{"/static/", http.StatusNoContent, ""},
+		{"/static/app.css", http.StatusNoContent, ""},
+		{"/docs/", http.StatusNoContent, ""},
+		{"/users/", http.StatusMovedPermanently, "/users"},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+		if rr.Code != tt.status {
+			t.Errorf("%s: got status %d, want %d", tt.target, rr.Code, tt.status)
+		}
+		if loc := rr.Header().Get("Location"); loc != tt.location {
+			t.Errorf("%s: got location %q, want %q", tt.target, loc, tt.location)
+		}
+	}
+}
+
+func TestMethodOverride(t *testing.T) {
+	var got string
+	h := middleware.MethodOverride(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Method
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		header string
+		form   string
+		want   string
+	}{
+		{"form field", http.MethodPost, "", "_method=delete", http.MethodDelete},
+		{"header", http.MethodPost, http.MethodPatch, "", http.MethodPatch},
+		{"unsupported method", http.MethodPost, "", "_method=CONNECT", http.MethodPost},
+		{"not a post", http.MethodGet, http.MethodDelete, "", http.MethodGet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.form))
+			req.Header.Set(ghttp.HeaderContentType, ghttp.MimeFormUrlEncoded)
+			if tt.header != "" {
+				req.Header.Set(middleware.HeaderMethodOverride, tt.header)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("got method %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
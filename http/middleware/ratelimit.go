@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+	"github.com/ferdiebergado/gopherkit/internal/expiring"
+)
+
+// Rate limit headers
+const (
+	HeaderRetryAfter         = "Retry-After"
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+)
+
+const defaultSweepInterval = time.Minute
+
+// Rate allows Requests per Period, refilled continuously as a token bucket.
+// Burst is the size of the bucket and defaults to Requests.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (rt Rate) burst() int {
+	if rt.Burst > 0 {
+		return rt.Burst
+	}
+	return rt.Requests
+}
+
+// perSecond returns how many tokens are added to the bucket every second.
+func (rt Rate) perSecond() float64 {
+	return float64(rt.Requests) / rt.Period.Seconds()
+}
+
+// RateLimitResult is the outcome of taking a token from a bucket.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+
+	// RetryAfter is the time until the next request is allowed.
+	// It is zero when the request was allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore keeps the buckets of a rate limiter.
+type RateLimitStore interface {
+	// Take removes a token from the bucket of key, creating a full bucket
+	// for unknown keys.
+	Take(ctx context.Context, key string, rate Rate) (RateLimitResult, error)
+}
+
+// RateLimitOptions configures the RateLimit middleware.
+type RateLimitOptions struct {
+	Rate Rate
+
+	// KeyFunc identifies the client. Requests with an empty key are not limited.
+	// Defaults to the host of r.RemoteAddr, which is the proxy's address when
+	// the server sits behind one; install RealIP with the trusted proxies
+	// before RateLimit to key on the client instead.
+	KeyFunc func(*http.Request) string
+
+	// Store defaults to a new MemoryStore.
+	Store RateLimitStore
+}
+
+// remoteHost returns the host of the connection's remote address.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimit rejects clients that exceed their rate with a 429 problem and a
+// Retry-After header. Every limited response carries RateLimit-* headers.
+// Requests are let through when the store fails.
+func RateLimit(opts RateLimitOptions) Middleware {
+	if opts.Rate.Requests <= 0 || opts.Rate.Period <= 0 {
+		panic("middleware: rate limit needs positive Requests and Period")
+	}
+	if opts.KeyFunc == nil {
+		opts.KeyFunc = remoteHost
+	}
+	if opts.Store == nil {
+		opts.Store = NewMemoryStore()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := opts.KeyFunc(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := opts.Store.Take(r.Context(), key, opts.Rate)
+			if err != nil {
+				slog.Error("rate limit store", "reason", err, "method", r.Method, "path", r.URL.Path)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			h.Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			h.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				h.Set(HeaderRetryAfter, strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				response.TooManyRequests(w, "Too many requests. Try again later.")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryStore keeps token buckets in memory.
+// Buckets that have refilled completely are evicted, since a new bucket
+// would be in the same state.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets *expiring.Map[*bucket]
+
+	// SweepInterval is how often full buckets are evicted. Defaults to a minute.
+	SweepInterval time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:       expiring.New[*bucket](),
+		SweepInterval: defaultSweepInterval,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rate Rate) (RateLimitResult, error) {
+	now := time.Now()
+	burst := float64(rate.burst())
+	perSecond := rate.perSecond()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buckets.Sweep(now, s.SweepInterval)
+
+	// A bucket expires once it is full again.
+	b, ok := s.buckets.Get(key, now)
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+	}
+
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	res := RateLimitResult{Limit: rate.burst()}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = secondsToDuration((burst - b.tokens) / perSecond)
+	s.buckets.Set(key, b, now.Add(res.Reset))
+
+	return res, nil
+}
+
+// Len returns the number of buckets in the store.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buckets.Len()
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,170 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+)
+
+func serveLimited(h http.Handler, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = ip + ":1234"
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRateLimit(t *testing.T) {
+	h := middleware.RateLimit(middleware.RateLimitOptions{
+		Rate: middleware.Rate{Requests: 3, Period: time.Minute},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := range 3 {
+		rr := serveLimited(h, "10.0.0.1")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("request %d: got status %d", i, rr.Code)
+		}
+		if got := rr.Header().Get(middleware.HeaderRateLimitLimit); got != "3" {
+			t.Errorf("request %d: got limit %q", i, got)
+		}
+		if got := rr.Header().Get(middleware.HeaderRateLimitRemaining); got != strconv.Itoa(2-i) {
+			t.Errorf("request %d: got remaining %q", i, got)
+		}
+	}
+
+	rr := serveLimited(h, "10.0.0.1")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusTooManyRequests)
+	}
+	if got := rr.Header().Get(ghttp.HeaderContentType); got != ghttp.MimeProblemJSON {
+		t.Errorf("got content type %q", got)
+	}
+	// One token comes back every 20 seconds.
+	if got := rr.Header().Get(middleware.HeaderRetryAfter); got != "20" {
+		t.Errorf("got Retry-After %q, want 20", got)
+	}
+	if got := rr.Header().Get(middleware.HeaderRateLimitReset); got != "60" {
+		t.Errorf("got reset %q, want 60", got)
+	}
+
+	if rr := serveLimited(h, "10.0.0.2"); rr.Code != http.StatusOK {
+		t.Errorf("other client got status %d", rr.Code)
+	}
+}
+
+func TestRateLimitIgnoresForwardedHeaders(t *testing.T) {
+	h := middleware.RateLimit(middleware.RateLimitOptions{
+		Rate: middleware.Rate{Requests: 1, Period: time.Minute},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", ip)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		want := http.StatusOK
+		if i > 0 {
+			want = http.StatusTooManyRequests
+		}
+		if rr.Code != want {
+			t.Errorf("request %d: got status %d, want %d", i, rr.Code, want)
+		}
+	}
+}
+
+func TestRateLimitRefill(t *testing.T) {
+	h := middleware.RateLimit(middleware.RateLimitOptions{
+		Rate: middleware.Rate{Requests: 1, Period: 20 * time.Millisecond},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serveLimited(h, "10.0.0.1")
+	if rr := serveLimited(h, "10.0.0.1"); rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusTooManyRequests)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if rr := serveLimited(h, "10.0.0.1"); rr.Code != http.StatusOK {
+		t.Errorf("got status %d after refill", rr.Code)
+	}
+}
+
+func TestRateLimitKeyFunc(t *testing.T) {
+	h := middleware.RateLimit(middleware.RateLimitOptions{
+		Rate: middleware.Rate{Requests: 1, Period: time.Minute},
+		KeyFunc: func(r *http.Request) string {
+			return r.Header.Get("X-API-Key")
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	serve := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	if serve("a") != http.StatusOK || serve("a") != http.StatusTooManyRequests {
+		t.Error("key a should be limited after one request")
+	}
+	if serve("b") != http.StatusOK {
+		t.Error("key b should be limited separately")
+	}
+	if serve("") != http.StatusOK || serve("") != http.StatusOK {
+		t.Error("requests without a key should not be limited")
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, middleware.Rate) (middleware.RateLimitResult, error) {
+	return middleware.RateLimitResult{}, errors.New("store unavailable")
+}
+
+func TestRateLimitStoreFailure(t *testing.T) {
+	h := middleware.RateLimit(middleware.RateLimitOptions{
+		Rate:  middleware.Rate{Requests: 1, Period: time.Minute},
+		Store: failingStore{},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	if rr := serveLimited(h, "10.0.0.1"); rr.Code != http.StatusOK {
+		t.Errorf("got status %d, want requests let through", rr.Code)
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	store := middleware.NewMemoryStore()
+	store.SweepInterval = 0
+	rate := middleware.Rate{Requests: 10, Period: 100 * time.Millisecond, Burst: 2}
+
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := store.Take(ctx, key, rate); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if store.Len() != 3 {
+		t.Fatalf("got %d buckets, want 3", store.Len())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, err := store.Take(ctx, "d", rate); err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != 1 {
+		t.Errorf("got %d buckets after sweep, want 1", store.Len())
+	}
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP replaces r.RemoteAddr with the client address reported by the
+// trusted proxies in front of the server. Forwarding headers are only honored
+// when the direct peer is in one of the trusted ranges. X-Forwarded-For is
+// walked from the right, skipping trusted proxies, so entries a client
+// prepends itself are never used; X-Real-IP is the fallback when it is absent.
+// It panics when trustedProxies is empty.
+func RealIP(trustedProxies []netip.Prefix) Middleware {
+	if len(trustedProxies) == 0 {
+		panic("middleware: RealIP needs at least one trusted proxy range")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host, port, err := net.SplitHostPort(r.RemoteAddr)
+			if err != nil {
+				host, port = r.RemoteAddr, "0"
+			}
+
+			if peer, err := netip.ParseAddr(host); err == nil && trusted(peer, trustedProxies) {
+				if client, ok := clientAddr(r, trustedProxies); ok {
+					r.RemoteAddr = net.JoinHostPort(client.String(), port)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientAddr returns the rightmost untrusted X-Forwarded-For entry. When every
+// entry is a trusted proxy the leftmost one is used, and parsing stops at the
+// first malformed entry since nothing beyond it can be vouched for.
+func clientAddr(r *http.Request, proxies []netip.Prefix) (netip.Addr, bool) {
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	if len(hops) == 0 {
+		addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+		return addr.Unmap(), err == nil
+	}
+
+	var client netip.Addr
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap()
+		if !trusted(client, proxies) {
+			break
+		}
+	}
+	return client, client.IsValid()
+}
+
+func trusted(addr netip.Addr, proxies []netip.Prefix) bool {
+	addr = addr.Unmap()
+	for _, p := range proxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+// Recover turns panics into server errors, logged with their stack trace.
+// http.ErrAbortHandler is re-panicked so the server can abort the response.
+// Panics after the response was started are only logged, since the status
+// was already sent.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := newStatusWriter(w)
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			err, ok := rec.(error)
+			if !ok {
+				err = fmt.Errorf("%v", rec)
+			}
+			err = fmt.Errorf("panic: %w", err)
+
+			if sw.wroteHeader {
+				slog.Error("server error", "reason", err, "stack_trace", string(debug.Stack()))
+				return
+			}
+			response.ServerError(w, err)
+		}()
+
+		next.ServeHTTP(sw, r)
+	})
+}
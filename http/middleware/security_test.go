@@ -0,0 +1,124 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/request"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestSecureHeadersDefaults(t *testing.T) {
+	h := middleware.SecureHeaders(middleware.SecurityOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if nonce := request.CSPNonce(r); nonce != "" {
+			t.Errorf("got nonce %q without a policy", nonce)
+		}
+	}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := map[string]string{
+		middleware.HeaderStrictTransportSecurity: "max-age=31536000",
+		middleware.HeaderContentTypeOptions:      "nosniff",
+		middleware.HeaderFrameOptions:            "DENY",
+		middleware.HeaderReferrerPolicy:          "strict-origin-when-cross-origin",
+		middleware.HeaderContentSecurityPolicy:   "",
+	}
+	for name, value := range want {
+		if got := rr.Header().Get(name); got != value {
+			t.Errorf("%s: got %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestSecureHeadersOptions(t *testing.T) {
+	h := middleware.SecureHeaders(middleware.SecurityOptions{
+		HSTSMaxAge:            time.Hour,
+		HSTSIncludeSubdomains: true,
+		HSTSPreload:           true,
+		FrameOptions:          "-",
+		ReferrerPolicy:        "no-referrer",
+		CSP:                   middleware.NewCSP().Add("default-src", middleware.SourceSelf).Add("img-src", "*"),
+		CSPReportOnly:         true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := map[string]string{
+		middleware.HeaderStrictTransportSecurity:         "max-age=3600; includeSubDomains; preload",
+		middleware.HeaderFrameOptions:                    "",
+		middleware.HeaderReferrerPolicy:                  "no-referrer",
+		middleware.HeaderContentSecurityPolicy:           "",
+		middleware.HeaderContentSecurityPolicyReportOnly: "default-src 'self'; img-src *",
+	}
+	for name, value := range want {
+		if got := rr.Header().Get(name); got != value {
+			t.Errorf("%s: got %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestCSPBuild(t *testing.T) {
+	csp := middleware.NewCSP().
+		Add("script-src", middleware.SourceSelf).
+		Add("style-src", middleware.SourceSelf).
+		Add("script-src", middleware.SourceNonce).
+		Set("style-src", middleware.SourceNone)
+
+	if !csp.UsesNonce() {
+		t.Error("policy should use a nonce")
+	}
+
+	want := "script-src 'self' 'nonce-abc'; style-src 'none'"
+	if got := csp.Build("abc"); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSecureHeadersNonce(t *testing.T) {
+	rd, err := response.NewRenderer(response.RendererConfig{
+		FS: fstest.MapFS{
+			"layout.html":     {Data: []byte(`<script nonce="{{cspNonce}}">{{template "content" .}}</script>`)},
+			"pages/home.html": {Data: []byte(`{{define "content"}}run(){{end}}`)},
+		},
+		RequestFuncs: []response.RequestFuncs{response.CSPNonceFuncs},
+	})
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+
+	h := middleware.SecureHeaders(middleware.SecurityOptions{CSP: middleware.DefaultCSP()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rd.Render(w, r, http.StatusOK, "home", nil)
+		}))
+
+	seen := make(map[string]bool)
+	for range 2 {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		policy := rr.Header().Get(middleware.HeaderContentSecurityPolicy)
+		_, rest, ok := strings.Cut(policy, "script-src 'self' 'nonce-")
+		if !ok {
+			t.Fatalf("policy has no script nonce: %q", policy)
+		}
+		nonce, _, _ := strings.Cut(rest, "'")
+
+		if seen[nonce] {
+			t.Errorf("nonce %q was reused", nonce)
+		}
+		seen[nonce] = true
+
+		want := `<script nonce="` + nonce + `">run()</script>`
+		if got := rr.Body.String(); got != want {
+			t.Errorf("got body %q, want %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+// TrailingSlash redirects paths ending in a slash to the same path without it.
+// GET and HEAD requests get a 301; other methods get a 308 so the method and
+// body are preserved. Leading slashes and backslashes are collapsed into one,
+// so //host/ cannot turn into a redirect to another host.
+//
+// routePattern, such as MuxPattern(mux) or Router.Pattern, keeps paths that
+// are routed with their slash, like subtrees and {path...} wildcards, from
+// being redirected. A catch-all "/" route does not count. It may be nil when
+// no route ends in a slash.
+func TrailingSlash(routePattern func(r *http.Request) string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.EscapedPath()
+			if len(path) <= 1 || !strings.HasSuffix(path, "/") || routesSlash(routePattern, r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			target := "/" + strings.TrimLeft(strings.TrimRight(path, "/"), "/\\")
+			if !response.IsLocalURL(target) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.URL.RawQuery != "" {
+				target += "?" + r.URL.RawQuery
+			}
+
+			status := http.StatusPermanentRedirect
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+
+			http.Redirect(w, r, target, status)
+		})
+	}
+}
+
+// routesSlash reports whether the request matches a route other than the
+// catch-all "/", which means the slash is part of the route.
+func routesSlash(routePattern func(r *http.Request) string, r *http.Request) bool {
+	if routePattern == nil {
+		return false
+	}
+
+	pattern := routePattern(r)
+	if pattern == "" {
+		return false
+	}
+
+	// Patterns are [METHOD ][HOST]/PATH.
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = rest
+	}
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i:]
+	}
+	return pattern != "/"
+}
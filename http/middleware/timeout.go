@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+// Timeout sets a deadline on the request context.
+// Handlers must watch the context; if one gives up at the deadline without
+// writing a response, a 503 problem is sent.
+func Timeout(d time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			sw := newStatusWriter(w)
+			next.ServeHTTP(sw, r.WithContext(ctx))
+
+			if !sw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				response.WriteProblem(w, response.NewProblem(http.StatusServiceUnavailable, "The request timed out."))
+			}
+		})
+	}
+}
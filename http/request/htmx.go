@@ -0,0 +1,23 @@
+package request
+
+import (
+	"net/http"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+// IsHTMX reports whether the request was made by htmx.
+func IsHTMX(r *http.Request) bool {
+	return r.Header.Get(ghttp.HeaderHXRequest) == "true"
+}
+
+// IsBoosted reports whether the request came from an hx-boost element.
+func IsBoosted(r *http.Request) bool {
+	return r.Header.Get(ghttp.HeaderHXBoosted) == "true"
+}
+
+// WantsFragment reports whether the request only needs a page fragment.
+// Boosted navigations and history restores expect the full page.
+func WantsFragment(r *http.Request) bool {
+	return IsHTMX(r) && !IsBoosted(r) && r.Header.Get(ghttp.HeaderHXHistoryRestoreRequest) != "true"
+}
@@ -0,0 +1,21 @@
+package request
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// WithID returns a context carrying the request id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// ID returns the id assigned to the request, or "" if there is none.
+func ID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey).(string)
+	return id
+}
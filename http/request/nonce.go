@@ -0,0 +1,19 @@
+package request
+
+import (
+	"context"
+	"net/http"
+)
+
+const cspNonceKey contextKey = "cspNonce"
+
+// WithCSPNonce returns a context carrying the Content-Security-Policy nonce.
+func WithCSPNonce(ctx context.Context, nonce string) context.Context {
+	return context.WithValue(ctx, cspNonceKey, nonce)
+}
+
+// CSPNonce returns the nonce of the request's Content-Security-Policy, or "" if there is none.
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey).(string)
+	return nonce
+}
@@ -0,0 +1,137 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/ferdiebergado/gopherkit"
+	"github.com/ferdiebergado/gopherkit/signer"
+)
+
+// Query parameters used for pagination
+const (
+	ParamPage    = "page"
+	ParamPerPage = "per_page"
+	ParamCursor  = "cursor"
+	ParamLimit   = "limit"
+
+	defaultPerPage = 20
+	maxPerPage     = 100
+	maxPage        = 10_000
+)
+
+// PageLimits caps the page sizes that clients can request.
+type PageLimits struct {
+	// Default is used when no size is requested. Defaults to 20.
+	Default int
+
+	// Max is the largest accepted size. Defaults to 100.
+	Max int
+
+	// MaxPage is the last page that can be requested; later pages are
+	// clamped to it. It bounds the offsets sent to the database.
+	// Defaults to 10000.
+	MaxPage int
+}
+
+func (l PageLimits) clamp(size int) int {
+	if l.Default <= 0 {
+		l.Default = defaultPerPage
+	}
+	if l.Max <= 0 {
+		l.Max = maxPerPage
+	}
+
+	switch {
+	case size <= 0:
+		return min(l.Default, l.Max)
+	case size > l.Max:
+		return l.Max
+	default:
+		return size
+	}
+}
+
+// PageParams are offset pagination parameters.
+type PageParams struct {
+	Page    int
+	PerPage int
+}
+
+// Offset returns the number of items before the page.
+// It never overflows, even for hand-built params.
+func (p PageParams) Offset() int {
+	if p.Page <= 1 || p.PerPage <= 0 {
+		return 0
+	}
+	if p.Page-1 > math.MaxInt/p.PerPage {
+		return math.MaxInt
+	}
+	return (p.Page - 1) * p.PerPage
+}
+
+// Page parses the page and per_page query parameters.
+// Invalid values fall back to the first page and the default size, and
+// pages past MaxPage are clamped to it.
+func Page(r *http.Request, limits PageLimits) PageParams {
+	q := r.URL.Query()
+
+	if limits.MaxPage <= 0 {
+		limits.MaxPage = maxPage
+	}
+
+	page := gopherkit.ParseInt(q.Get(ParamPage), 1)
+	page = min(max(page, 1), limits.MaxPage)
+
+	return PageParams{
+		Page:    page,
+		PerPage: limits.clamp(gopherkit.ParseInt(q.Get(ParamPerPage), 0)),
+	}
+}
+
+// CursorParams are cursor pagination parameters.
+// HasCursor is false on the first page.
+type CursorParams[T any] struct {
+	Cursor    T
+	HasCursor bool
+	Limit     int
+}
+
+// Cursor parses the cursor and limit query parameters.
+// The cursor must have been created by EncodeCursor with the same signer,
+// which should be created with its own purpose such as "cursor";
+// a tampered or malformed cursor is reported as a ValidationError.
+func Cursor[T any](r *http.Request, s *signer.Signer, limits PageLimits) (CursorParams[T], error) {
+	q := r.URL.Query()
+
+	params := CursorParams[T]{
+		Limit: limits.clamp(gopherkit.ParseInt(q.Get(ParamLimit), 0)),
+	}
+
+	token := q.Get(ParamCursor)
+	if token == "" {
+		return params, nil
+	}
+
+	payload, err := s.Verify(token)
+	if err != nil {
+		return params, ValidationError{ParamCursor: "is invalid"}
+	}
+	if err := json.Unmarshal(payload, &params.Cursor); err != nil {
+		return params, ValidationError{ParamCursor: "is invalid"}
+	}
+	params.HasCursor = true
+
+	return params, nil
+}
+
+// EncodeCursor turns a position, such as the last seen id, into an opaque signed cursor.
+func EncodeCursor(s *signer.Signer, position any) (string, error) {
+	payload, err := json.Marshal(position)
+	if err != nil {
+		return "", fmt.Errorf("encode cursor: %w", err)
+	}
+	return s.Sign(payload), nil
+}
@@ -0,0 +1,100 @@
+package request_test
+
+import (
+	"errors"
+	"math"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/http/request"
+	"github.com/ferdiebergado/gopherkit/signer"
+)
+
+func TestPage(t *testing.T) {
+	limits := request.PageLimits{Default: 10, Max: 50, MaxPage: 100}
+
+	tests := []struct {
+		query   string
+		page    int
+		perPage int
+		offset  int
+	}{
+		{"", 1, 10, 0},
+		{"page=3&per_page=20", 3, 20, 40},
+		{"page=-1&per_page=0", 1, 10, 0},
+		{"page=abc&per_page=500", 1, 50, 0},
+		{"page=9223372036854775807&per_page=50", 100, 50, 4950},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/users?"+tt.query, nil)
+
+		p := request.Page(req, limits)
+
+		if p.Page != tt.page || p.PerPage != tt.perPage || p.Offset() != tt.offset {
+			t.Errorf("%q: got %+v offset %d, want page %d per page %d offset %d", tt.query, p, p.Offset(), tt.page, tt.perPage, tt.offset)
+		}
+	}
+}
+
+func TestPageDefaultMaxPage(t *testing.T) {
+	req := httptest.NewRequest("GET", "/users?page=9223372036854775807&per_page=100", nil)
+
+	p := request.Page(req, request.PageLimits{})
+	if p.Page != 10_000 || p.Offset() != 999_900 {
+		t.Errorf("got %+v offset %d, want page 10000 offset 999900", p, p.Offset())
+	}
+}
+
+func TestOffsetOverflow(t *testing.T) {
+	tests := []struct {
+		params request.PageParams
+		want   int
+	}{
+		{request.PageParams{}, 0},
+		{request.PageParams{Page: 3, PerPage: -5}, 0},
+		{request.PageParams{Page: math.MaxInt, PerPage: 100}, math.MaxInt},
+	}
+
+	for _, tt := range tests {
+		if got := tt.params.Offset(); got != tt.want {
+			t.Errorf("%+v: got offset %d, want %d", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	s := signer.New([]byte("0123456789abcdef0123456789abcdef"), "cursor")
+
+	type position struct {
+		ID int `json:"id"`
+	}
+
+	token, err := request.EncodeCursor(s, position{ID: 42})
+	if err != nil {
+		t.Fatalf("encode cursor: %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/users?limit=1000&cursor="+url.QueryEscape(token), nil)
+	params, err := request.Cursor[position](req, s, request.PageLimits{})
+	if err != nil {
+		t.Fatalf("cursor: %v", err)
+	}
+	if !params.HasCursor || params.Cursor.ID != 42 || params.Limit != 100 {
+		t.Errorf("got %+v", params)
+	}
+
+	req = httptest.NewRequest("GET", "/users", nil)
+	params, err = request.Cursor[position](req, s, request.PageLimits{})
+	if err != nil || params.HasCursor || params.Limit != 20 {
+		t.Errorf("first page: got %+v, %v", params, err)
+	}
+
+	req = httptest.NewRequest("GET", "/users?cursor="+url.QueryEscape(token+"x"), nil)
+	_, err = request.Cursor[position](req, s, request.PageLimits{})
+	var validationErr request.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("tampered cursor: got error %v, want a ValidationError", err)
+	}
+}
@@ -2,19 +2,19 @@ package request
 
 import (
 	"encoding/json"
-	"fmt"
 	"net"
 	"net/http"
 	"strings"
 )
 
-// Decodes the JSON payload from the request body
+// Decodes the JSON payload from the request body.
+// Decoding failures are returned as a *DecodeError.
 func JSON[T any](r *http.Request) (T, error) {
 	var v T
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	if err := dec.Decode(&v); err != nil {
-		return v, fmt.Errorf("decode json: %w", err)
+		return v, &DecodeError{Err: err}
 	}
 	return v, nil
 }
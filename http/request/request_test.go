@@ -1,7 +1,9 @@
 package request_test
 
 import (
+	"errors"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/ferdiebergado/gopherkit/http/request"
@@ -81,3 +83,52 @@ func TestGetIPAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONDecodeError(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"unknown":true}`))
+
+	_, err := request.JSON[struct{}](req)
+
+	var decodeErr *request.DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("got error %v, want a *request.DecodeError", err)
+	}
+	if !strings.HasPrefix(err.Error(), "decode json: ") {
+		t.Errorf("got error message %q", err.Error())
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := request.ValidationError{"name": "is required", "email": "is invalid"}
+
+	want := "validation failed: email is invalid, name is required"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+}
+
+func TestWantsFragment(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{"plain request", nil, false},
+		{"htmx request", map[string]string{"HX-Request": "true"}, true},
+		{"boosted request", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, false},
+		{"history restore", map[string]string{"HX-Request": "true", "HX-History-Restore-Request": "true"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			if got := request.WantsFragment(req); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package response
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+const sniffLen = 512
+
+// ContentDisposition formats an RFC 6266 Content-Disposition value.
+// Filenames that are not plain ASCII get an ASCII fallback and an
+// RFC 5987 encoded filename* parameter.
+func ContentDisposition(disposition, filename string) string {
+	filename = filepath.Base(filename)
+	fallback := asciiFilename(filename)
+
+	value := disposition + `; filename="` + fallback + `"`
+	if fallback != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// Attachment sends content as a file download.
+// Range and conditional requests are handled by http.ServeContent, and the
+// content type is derived from the filename or sniffed from the content.
+func Attachment(w http.ResponseWriter, r *http.Request, filename string, modtime time.Time, content io.ReadSeeker) {
+	w.Header().Set(ghttp.HeaderContentDisposition, ContentDisposition("attachment", filename))
+	http.ServeContent(w, r, filename, modtime, content)
+}
+
+// Inline is like Attachment but lets the browser display the file.
+func Inline(w http.ResponseWriter, r *http.Request, filename string, modtime time.Time, content io.ReadSeeker) {
+	w.Header().Set(ghttp.HeaderContentDisposition, ContentDisposition("inline", filename))
+	http.ServeContent(w, r, filename, modtime, content)
+}
+
+// AttachmentReader streams content that cannot seek as a file download.
+// A negative size means the length is unknown. An empty contentType is
+// derived from the filename or sniffed from the content.
+// Errors after the headers are sent are logged, since the status is already committed.
+func AttachmentReader(w http.ResponseWriter, r *http.Request, filename, contentType string, size int64, content io.Reader) {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		br := bufio.NewReaderSize(content, sniffLen)
+		head, _ := br.Peek(sniffLen)
+		contentType = http.DetectContentType(head)
+		content = br
+	}
+
+	h := w.Header()
+	h.Set(ghttp.HeaderContentType, contentType)
+	h.Set(ghttp.HeaderContentDisposition, ContentDisposition("attachment", filename))
+	h.Set("X-Content-Type-Options", "nosniff")
+	if size >= 0 {
+		h.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	written, err := io.Copy(w, content)
+	if err != nil {
+		attrs := []any{"reason", err, "filename", filename, "written", written, "method", r.Method, "path", r.URL.Path}
+		if r.Context().Err() != nil {
+			slog.Info("download aborted by client", attrs...)
+		} else {
+			slog.Error("stream download", attrs...)
+		}
+	}
+}
+
+// asciiFilename replaces characters that cannot appear in a quoted filename.
+func asciiFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' || r == '%' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// encodeRFC5987 percent-encodes every byte that is not an attr-char.
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
@@ -0,0 +1,87 @@
+package response_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestContentDisposition(t *testing.T) {
+	tests := []struct {
+		disposition, filename, want string
+	}{
+		{"attachment", "report.csv", `attachment; filename="report.csv"`},
+		{"inline", "dir/invoice.pdf", `inline; filename="invoice.pdf"`},
+		{"attachment", `say "hi".txt`, `attachment; filename="say _hi_.txt"; filename*=UTF-8''say%20%22hi%22.txt`},
+		{"attachment", "résumé.pdf", `attachment; filename="r_sum_.pdf"; filename*=UTF-8''r%C3%A9sum%C3%A9.pdf`},
+	}
+
+	for _, tt := range tests {
+		if got := response.ContentDisposition(tt.disposition, tt.filename); got != tt.want {
+			t.Errorf("ContentDisposition(%q, %q) = %q; want %q", tt.disposition, tt.filename, got, tt.want)
+		}
+	}
+}
+
+func TestAttachmentRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+	req.Header.Set("Range", "bytes=0-4")
+	rr := httptest.NewRecorder()
+
+	response.Attachment(rr, req, "export.csv", time.Now(), strings.NewReader("id,name\n1,gopher\n"))
+
+	if rr.Code != http.StatusPartialContent {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusPartialContent)
+	}
+	if rr.Body.String() != "id,na" {
+		t.Errorf("got body %q", rr.Body.String())
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("got content type %q", ct)
+	}
+	if cd := rr.Header().Get(ghttp.HeaderContentDisposition); cd != `attachment; filename="export.csv"` {
+		t.Errorf("got content disposition %q", cd)
+	}
+}
+
+func TestAttachmentReader(t *testing.T) {
+	pdf := "%PDF-1.7\n..."
+
+	tests := []struct {
+		name        string
+		filename    string
+		contentType string
+		size        int64
+		wantType    string
+		wantLength  string
+	}{
+		{"known size", "report.pdf", "", int64(len(pdf)), "application/pdf", "12"},
+		{"unknown size sniffed", "report", "", -1, "application/pdf", ""},
+		{"explicit type", "report", "application/x-custom", -1, "application/x-custom", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/download", nil)
+
+			response.AttachmentReader(rr, req, tt.filename, tt.contentType, tt.size, io.NopCloser(strings.NewReader(pdf)))
+
+			if ct := rr.Header().Get(ghttp.HeaderContentType); ct != tt.wantType {
+				t.Errorf("got content type %q, want %q", ct, tt.wantType)
+			}
+			if cl := rr.Header().Get("Content-Length"); cl != tt.wantLength {
+				t.Errorf("got content length %q, want %q", cl, tt.wantLength)
+			}
+			if rr.Body.String() != pdf {
+				t.Errorf("got body %q", rr.Body.String())
+			}
+		})
+	}
+}
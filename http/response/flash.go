@@ -0,0 +1,112 @@
+package response
+
+import (
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/ferdiebergado/gopherkit/signer"
+)
+
+const flashCookie = "flash"
+
+// FlashMessage is a one-shot message shown on the next rendered page.
+type FlashMessage struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Flash stores flash messages in a signed cookie until they are displayed.
+type Flash struct {
+	signer *signer.Signer
+
+	// CookieName defaults to flash.
+	CookieName string
+
+	// Secure restricts the cookie to HTTPS.
+	Secure bool
+}
+
+// Creates a Flash that signs its cookie with key.
+// It panics when key is shorter than signer.MinKeySize.
+func NewFlash(key []byte) *Flash {
+	return &Flash{
+		signer:     signer.New(key, "flash"),
+		CookieName: flashCookie,
+		Secure:     true,
+	}
+}
+
+// Set stores messages to be shown by the next rendered page.
+func (f *Flash) Set(w http.ResponseWriter, messages ...FlashMessage) {
+	b, err := json.Marshal(messages)
+	if err != nil {
+		slog.Error("encode flash messages", "reason", err)
+		return
+	}
+
+	http.SetCookie(w, f.cookie(f.signer.Sign(b), 0))
+}
+
+// Pop returns the pending messages and clears them.
+// Cookies that fail verification are discarded.
+func (f *Flash) Pop(w http.ResponseWriter, r *http.Request) []FlashMessage {
+	c, err := r.Cookie(f.CookieName)
+	if err != nil {
+		return nil
+	}
+
+	http.SetCookie(w, f.cookie("", -1))
+
+	payload, err := f.signer.Verify(c.Value)
+	if err != nil {
+		slog.Warn("invalid flash cookie", "reason", err)
+		return nil
+	}
+
+	var messages []FlashMessage
+	if err := json.Unmarshal(payload, &messages); err != nil {
+		slog.Warn("invalid flash cookie", "reason", err)
+		return nil
+	}
+
+	return messages
+}
+
+// TemplateFuncs exposes the pending messages to templates as flashes.
+// The messages are only cleared when a template calls flashes.
+func (f *Flash) TemplateFuncs(w http.ResponseWriter, r *http.Request) template.FuncMap {
+	var (
+		once     sync.Once
+		messages []FlashMessage
+	)
+
+	return template.FuncMap{
+		"flashes": func() []FlashMessage {
+			once.Do(func() {
+				messages = f.Pop(w, r)
+			})
+			return messages
+		},
+	}
+}
+
+// HTML is like the package-level HTML, but exposes the pending messages to
+// the templates as flashes.
+func (f *Flash) HTML(w http.ResponseWriter, r *http.Request, data any, templateFiles ...string) {
+	HTMLFuncs(w, f.TemplateFuncs(w, r), data, templateFiles...)
+}
+
+func (f *Flash) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     f.CookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   f.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
@@ -0,0 +1,132 @@
+package response_test
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+var flashKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestFlashRoundTrip(t *testing.T) {
+	flash := response.NewFlash(flashKey)
+
+	rr := httptest.NewRecorder()
+	flash.Set(rr, response.FlashMessage{Kind: "success", Message: "Saved."})
+	cookies := rr.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly {
+		t.Fatalf("expected one http-only cookie, got %v", cookies)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rr = httptest.NewRecorder()
+
+	messages := flash.Pop(rr, req)
+	if len(messages) != 1 || messages[0].Message != "Saved." {
+		t.Errorf("got messages %v", messages)
+	}
+
+	cleared := rr.Result().Cookies()
+	if len(cleared) != 1 || cleared[0].MaxAge >= 0 {
+		t.Errorf("flash cookie should be cleared, got %v", cleared)
+	}
+}
+
+func TestFlashRejectsTamperedCookie(t *testing.T) {
+	flash := response.NewFlash(flashKey)
+	forged := response.NewFlash([]byte("another key that is long enough!"))
+
+	rr := httptest.NewRecorder()
+	forged.Set(rr, response.FlashMessage{Kind: "error", Message: "forged"})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(rr.Result().Cookies()[0])
+
+	if messages := flash.Pop(httptest.NewRecorder(), req); messages != nil {
+		t.Errorf("tampered cookie should be ignored, got %v", messages)
+	}
+}
+
+func TestRendererExposesFlashes(t *testing.T) {
+	flash := response.NewFlash(flashKey)
+	fsys := newTestFS()
+	fsys["layout.html"] = &fstest.MapFile{Data: []byte(`{{range flashes}}[{{.Kind}}: {{.Message}}]{{end}}{{range flashes}}again{{end}}{{template "content" .}}`)}
+
+	rd, err := response.NewRenderer(response.RendererConfig{
+		FS:    fsys,
+		Funcs: template.FuncMap{"shout": strings.ToUpper},
+		Flash: flash,
+	})
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	flash.Set(rr, response.FlashMessage{Kind: "info", Message: "Welcome back"})
+	cookie := rr.Result().Cookies()[0]
+
+	for i, want := range []string{"[info: Welcome back]againHello Gopher", "Hello Gopher"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if i == 0 {
+			req.AddCookie(cookie)
+		}
+		rr = httptest.NewRecorder()
+
+		rd.Render(rr, req, http.StatusOK, "home", "Gopher")
+
+		if rr.Body.String() != want {
+			t.Errorf("render %d: got %q, want %q", i, rr.Body.String(), want)
+		}
+	}
+
+	var sb strings.Builder
+	if err := rd.Execute(&sb, "", "home", "Gopher"); err != nil {
+		t.Fatalf("execute without a request: %v", err)
+	}
+}
+
+func TestFlashHTML(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "templates"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	layout := `{{range flashes}}[{{.Kind}}: {{.Message}}]{{end}}{{template "content" .}}`
+	if err := os.WriteFile(filepath.Join(dir, "templates", "layout.html"), []byte(layout), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	page := filepath.Join(dir, "templates", "home.html")
+	if err := os.WriteFile(page, []byte(`{{define "content"}}Hello {{.}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	flash := response.NewFlash(flashKey)
+	rr := httptest.NewRecorder()
+	flash.Set(rr, response.FlashMessage{Kind: "success", Message: "Saved."})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(rr.Result().Cookies()[0])
+	rr = httptest.NewRecorder()
+
+	flash.HTML(rr, req, "Gopher", page)
+
+	if got := rr.Body.String(); got != "[success: Saved.]Hello Gopher" {
+		t.Errorf("got body %q", got)
+	}
+}
@@ -0,0 +1,264 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Funcs returns the built-in template functions merged with extra.
+// Later maps override earlier ones, including the built-ins.
+func Funcs(extra ...template.FuncMap) template.FuncMap {
+	funcs := templateFuncs()
+	for _, fm := range extra {
+		for name, fn := range fm {
+			funcs[name] = fn
+		}
+	}
+	return funcs
+}
+
+// AssetFuncs returns an asset function that resolves names through a manifest,
+// such as one mapping app.css to app.3f2a1c.css, and joins them with prefix.
+// Names missing from the manifest are used as is.
+func AssetFuncs(prefix string, manifest map[string]string) template.FuncMap {
+	return template.FuncMap{
+		"asset": func(name string) string {
+			if hashed, ok := manifest[name]; ok {
+				name = hashed
+			}
+			return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(name, "/")
+		},
+	}
+}
+
+// Creates a template funcmap
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Type casts
+		"attr": func(s string) template.HTMLAttr {
+			return template.HTMLAttr(s)
+		},
+		"safe": func(s string) template.HTML {
+			return template.HTML(s)
+		},
+		"url": func(s string) template.URL {
+			return template.URL(s)
+		},
+		"js": func(s string) template.JS {
+			return template.JS(s)
+		},
+		"jsstr": func(s string) template.JSStr {
+			return template.JSStr(s)
+		},
+		"css": func(s string) template.CSS {
+			return template.CSS(s)
+		},
+
+		// Formatting
+		"date":      formatDate,
+		"number":    formatNumber,
+		"currency":  formatCurrency,
+		"pluralize": pluralize,
+		"json":      toJSON,
+
+		// Builders
+		"dict": dict,
+		"list": func(values ...any) []any {
+			return values
+		},
+		"default": defaultValue,
+
+		// Strings
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"title":     title,
+		"trim":      strings.TrimSpace,
+		"truncate":  truncate,
+		"contains":  strings.Contains,
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+		"replace": func(old, new, s string) string {
+			return strings.ReplaceAll(s, old, new)
+		},
+		"split": func(sep, s string) []string {
+			return strings.Split(s, sep)
+		},
+		"join": func(sep string, elems []string) string {
+			return strings.Join(elems, sep)
+		},
+
+		// Assets, overridden by AssetFuncs
+		"asset": func(name string) string {
+			return name
+		},
+	}
+}
+
+// formatDate formats a time.Time or *time.Time; a nil or zero time yields "".
+func formatDate(layout string, v any) (string, error) {
+	switch t := v.(type) {
+	case time.Time:
+		if t.IsZero() {
+			return "", nil
+		}
+		return t.Format(layout), nil
+	case *time.Time:
+		if t == nil || t.IsZero() {
+			return "", nil
+		}
+		return t.Format(layout), nil
+	default:
+		return "", fmt.Errorf("date: unsupported type %T", v)
+	}
+}
+
+// formatNumber formats v with thousands separators and the given decimals.
+func formatNumber(decimals int, v any) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", fmt.Errorf("number: %w", err)
+	}
+
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if hasFrac {
+		b.WriteString("." + fracPart)
+	}
+
+	return b.String(), nil
+}
+
+// formatCurrency formats v with two decimals after the currency symbol.
+func formatCurrency(symbol string, v any) (string, error) {
+	n, err := formatNumber(2, v)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(n, "-") {
+		return "-" + symbol + n[1:], nil
+	}
+	return symbol + n, nil
+}
+
+// pluralize returns singular when count is one and plural otherwise.
+func pluralize(count any, singular, plural string) (string, error) {
+	n, err := toFloat(count)
+	if err != nil {
+		return "", fmt.Errorf("pluralize: %w", err)
+	}
+
+	if n == 1 {
+		return singular, nil
+	}
+	return plural, nil
+}
+
+// toJSON marshals v for embedding in a script.
+// The output is safe for HTML since json.Marshal escapes <, > and &.
+func toJSON(v any) (template.JS, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("json: %w", err)
+	}
+	return template.JS(b), nil
+}
+
+// dict builds a map from alternating keys and values.
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, errors.New("dict: odd number of arguments")
+	}
+
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+
+	return m, nil
+}
+
+// defaultValue returns v unless it is nil, zero or empty.
+func defaultValue(def, v any) any {
+	if v == nil {
+		return def
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	default:
+		if rv.IsZero() {
+			return def
+		}
+	}
+
+	return v
+}
+
+// title upper-cases the first letter of every word.
+func title(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		isStart := unicode.IsSpace(prev)
+		prev = r
+		if isStart {
+			return unicode.ToTitle(r)
+		}
+		return r
+	}, s)
+}
+
+// truncate shortens s to n characters, ending it with an ellipsis.
+func truncate(n int, s string) string {
+	if n < 1 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:n-1])) + "…"
+}
+
+// toFloat converts any numeric value to a float64.
+func toFloat(v any) (float64, error) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	default:
+		return 0, fmt.Errorf("unsupported type %T", v)
+	}
+}
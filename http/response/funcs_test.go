@@ -0,0 +1,95 @@
+package response_test
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestFuncs(t *testing.T) {
+	created := time.Date(2024, time.March, 9, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		tmpl string
+		data any
+		want string
+	}{
+		{"date", `{{ . | date "Jan 2, 2006" }}`, created, "Mar 9, 2024"},
+		{"date zero", `{{ . | date "2006" }}`, time.Time{}, ""},
+		{"number int", `{{ . | number 0 }}`, 1234567, "1,234,567"},
+		{"number float", `{{ . | number 2 }}`, -1234.567, "-1,234.57"},
+		{"number small", `{{ . | number 1 }}`, 12, "12.0"},
+		{"currency", `{{ . | currency "$" }}`, 1999.5, "$1,999.50"},
+		{"currency negative", `{{ . | currency "€" }}`, -5, "-€5.00"},
+		{"pluralize one", `{{ pluralize . "item" "items" }}`, 1, "item"},
+		{"pluralize many", `{{ pluralize . "item" "items" }}`, 3, "items"},
+		{"dict", `{{ with dict "name" "Gopher" "age" 13 }}{{ .name }} {{ .age }}{{ end }}`, nil, "Gopher 13"},
+		{"list", `{{ range list "a" "b" }}{{ . }}{{ end }}`, nil, "ab"},
+		{"default empty", `{{ . | default "Anonymous" }}`, "", "Anonymous"},
+		{"default set", `{{ . | default "Anonymous" }}`, "Gopher", "Gopher"},
+		{"default zero", `{{ . | default 10 }}`, 0, "10"},
+		{"title", `{{ title . }}`, "hello wide world", "Hello Wide World"},
+		{"truncate", `{{ . | truncate 6 }}`, "gophers unite", "gophe…"},
+		{"truncate short", `{{ . | truncate 20 }}`, "gophers", "gophers"},
+		{"replace", `{{ . | replace "-" " " }}`, "a-b-c", "a b c"},
+		{"split join", `{{ . | split "," | join " & " }}`, "a,b", "a &amp; b"},
+		{"json", `<script>var d = {{ json . }};</script>`, map[string]string{"x": "</script>"}, `<script>var d = {"x":"\u003c/script\u003e"};</script>`},
+		{"asset", `{{ asset "app.css" }}`, nil, "app.css"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("test").Funcs(response.Funcs()).Parse(tt.tmpl)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, tt.data); err != nil {
+				t.Fatalf("execute: %v", err)
+			}
+			if sb.String() != tt.want {
+				t.Errorf("got %q, want %q", sb.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFuncsMergesExtra(t *testing.T) {
+	funcs := response.Funcs(
+		template.FuncMap{"greet": func() string { return "hi" }},
+		response.AssetFuncs("/static/", map[string]string{"app.css": "app.3f2a.css"}),
+	)
+
+	tmpl := template.Must(template.New("test").Funcs(funcs).Parse(`{{ greet }} {{ asset "app.css" }} {{ asset "logo.png" }}`))
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, nil); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	want := "hi /static/app.3f2a.css /static/logo.png"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+}
+
+func TestFuncsErrors(t *testing.T) {
+	tests := []string{
+		`{{ dict "a" }}`,
+		`{{ dict 1 2 }}`,
+		`{{ "x" | number 2 }}`,
+		`{{ "x" | date "2006" }}`,
+	}
+
+	for _, src := range tests {
+		tmpl := template.Must(template.New("test").Funcs(response.Funcs()).Parse(src))
+		if err := tmpl.Execute(&strings.Builder{}, nil); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+}
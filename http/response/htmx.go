@@ -0,0 +1,60 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+// HXTrigger makes htmx trigger the given client-side events.
+func HXTrigger(w http.ResponseWriter, events ...string) {
+	w.Header().Set(ghttp.HeaderHXTrigger, strings.Join(events, ", "))
+}
+
+// HXTriggerDetail makes htmx trigger events with their detail payloads.
+func HXTriggerDetail(w http.ResponseWriter, events map[string]any) error {
+	b, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("encode hx-trigger: %w", err)
+	}
+	w.Header().Set(ghttp.HeaderHXTrigger, string(b))
+	return nil
+}
+
+// HXRedirect makes htmx perform a full client-side redirect to url.
+func HXRedirect(w http.ResponseWriter, url string) {
+	w.Header().Set(ghttp.HeaderHXRedirect, url)
+}
+
+// HXLocation makes htmx navigate to url without a full page reload.
+func HXLocation(w http.ResponseWriter, url string) {
+	w.Header().Set(ghttp.HeaderHXLocation, url)
+}
+
+// HXRefresh makes htmx reload the whole page.
+func HXRefresh(w http.ResponseWriter) {
+	w.Header().Set(ghttp.HeaderHXRefresh, "true")
+}
+
+// HXPushURL pushes url into the browser history.
+func HXPushURL(w http.ResponseWriter, url string) {
+	w.Header().Set(ghttp.HeaderHXPushURL, url)
+}
+
+// HXReplaceURL replaces the current url in the browser location bar.
+func HXReplaceURL(w http.ResponseWriter, url string) {
+	w.Header().Set(ghttp.HeaderHXReplaceURL, url)
+}
+
+// HXRetarget swaps the response into the element matching selector.
+func HXRetarget(w http.ResponseWriter, selector string) {
+	w.Header().Set(ghttp.HeaderHXRetarget, selector)
+}
+
+// HXReswap overrides how the response is swapped, such as outerHTML.
+func HXReswap(w http.ResponseWriter, swap string) {
+	w.Header().Set(ghttp.HeaderHXReswap, swap)
+}
@@ -0,0 +1,90 @@
+package response_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestRendererRenderFragment(t *testing.T) {
+	rd := newTestRenderer(t, newTestFS(), false)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"full page", nil, "<main>Hello Gopher</main><footer>BYE</footer>"},
+		{"htmx", map[string]string{ghttp.HeaderHXRequest: "true"}, "Hello Gopher"},
+		{"boosted", map[string]string{ghttp.HeaderHXRequest: "true", ghttp.HeaderHXBoosted: "true"}, "<main>Hello Gopher</main><footer>BYE</footer>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			rr := httptest.NewRecorder()
+			rd.Render(rr, req, http.StatusOK, "home", "Gopher")
+
+			if rr.Body.String() != tt.want {
+				t.Errorf("got %q, want %q", rr.Body.String(), tt.want)
+			}
+			if vary := rr.Header().Get(ghttp.HeaderVary); vary != ghttp.HeaderHXRequest {
+				t.Errorf("got Vary %q, want %q", vary, ghttp.HeaderHXRequest)
+			}
+		})
+	}
+}
+
+func TestRendererRenderBlock(t *testing.T) {
+	rd := newTestRenderer(t, newTestFS(), false)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	rr := httptest.NewRecorder()
+	rd.RenderBlock(rr, req, http.StatusOK, "users/show", "footer", nil)
+
+	if rr.Body.String() != "<footer>BYE</footer>" {
+		t.Errorf("got %q", rr.Body.String())
+	}
+
+	err := rd.ExecuteBlock(&strings.Builder{}, "home", "missing", nil)
+	if !errors.Is(err, response.ErrTemplateNotFound) {
+		t.Errorf("got error %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestHXHeaders(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	response.HXTrigger(rr, "saved", "closeModal")
+	response.HXRedirect(rr, "/login")
+	response.HXRetarget(rr, "#errors")
+	response.HXReswap(rr, "outerHTML")
+
+	want := map[string]string{
+		ghttp.HeaderHXTrigger:  "saved, closeModal",
+		ghttp.HeaderHXRedirect: "/login",
+		ghttp.HeaderHXRetarget: "#errors",
+		ghttp.HeaderHXReswap:   "outerHTML",
+	}
+	for k, v := range want {
+		if got := rr.Header().Get(k); got != v {
+			t.Errorf("got %s %q, want %q", k, got, v)
+		}
+	}
+
+	if err := response.HXTriggerDetail(rr, map[string]any{"notify": map[string]string{"level": "info"}}); err != nil {
+		t.Fatalf("trigger detail: %v", err)
+	}
+	if got := rr.Header().Get(ghttp.HeaderHXTrigger); got != `{"notify":{"level":"info"}}` {
+		t.Errorf("got HX-Trigger %q", got)
+	}
+}
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+// CSPNonceFuncs exposes the Content-Security-Policy nonce of the request to
+// templates as cspNonce, for use as RendererConfig.RequestFuncs or with HTMLFuncs:
+//
+//	<script nonce="{{cspNonce}}">...</script>
+func CSPNonceFuncs(_ http.ResponseWriter, r *http.Request) template.FuncMap {
+	nonce := request.CSPNonce(r)
+	return template.FuncMap{
+		"cspNonce": func() string {
+			return nonce
+		},
+	}
+}
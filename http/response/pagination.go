@@ -0,0 +1,125 @@
+package response
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+// PageMeta describes where a page sits in a list.
+// The links are relative to the current request.
+type PageMeta struct {
+	Total   *int   `json:"total,omitempty"`
+	Page    int    `json:"page,omitempty"`
+	PerPage int    `json:"per_page,omitempty"`
+	Limit   int    `json:"limit,omitempty"`
+	First   string `json:"first,omitempty"`
+	Last    string `json:"last,omitempty"`
+	Next    string `json:"next,omitempty"`
+	Prev    string `json:"prev,omitempty"`
+}
+
+// OffsetPage builds the metadata of an offset paginated list.
+// Params without a page size describe a single page holding every item.
+func OffsetPage(r *http.Request, p request.PageParams, total int) PageMeta {
+	p.Page = max(p.Page, 1)
+	lastPage := 1
+	if p.PerPage > 0 {
+		lastPage = max(1, (total+p.PerPage-1)/p.PerPage)
+	}
+
+	link := func(page int) string {
+		return pageURL(r, map[string]string{
+			request.ParamPage:    strconv.Itoa(page),
+			request.ParamPerPage: strconv.Itoa(p.PerPage),
+		})
+	}
+
+	meta := PageMeta{
+		Total:   &total,
+		Page:    p.Page,
+		PerPage: p.PerPage,
+		First:   link(1),
+		Last:    link(lastPage),
+	}
+	if p.Page < lastPage {
+		meta.Next = link(p.Page + 1)
+	}
+	if p.Page > 1 {
+		meta.Prev = link(min(p.Page-1, lastPage))
+	}
+
+	return meta
+}
+
+// CursorPage builds the metadata of a cursor paginated list.
+// Empty cursors mean there is no page in that direction.
+func CursorPage(r *http.Request, limit int, next, prev string) PageMeta {
+	link := func(cursor string) string {
+		return pageURL(r, map[string]string{
+			request.ParamCursor: cursor,
+			request.ParamLimit:  strconv.Itoa(limit),
+		})
+	}
+
+	meta := PageMeta{
+		Limit: limit,
+		First: pageURL(r, map[string]string{
+			request.ParamCursor: "",
+			request.ParamLimit:  strconv.Itoa(limit),
+		}),
+	}
+	if next != "" {
+		meta.Next = link(next)
+	}
+	if prev != "" {
+		meta.Prev = link(prev)
+	}
+
+	return meta
+}
+
+// Paginated sends items in a {"data":...,"meta":...} envelope and
+// advertises the neighbouring pages in an RFC 8288 Link header.
+func Paginated(w http.ResponseWriter, r *http.Request, status int, items any, meta PageMeta) {
+	if link := LinkHeader(meta); link != "" {
+		w.Header().Set("Link", link)
+	}
+
+	JSONWith(w, status, items, JSONOptions{EscapeHTML: true, Envelope: true, Meta: meta})
+}
+
+// LinkHeader formats the links of meta as an RFC 8288 Link header value.
+func LinkHeader(meta PageMeta) string {
+	var links []string
+	for _, l := range []struct{ rel, url string }{
+		{"first", meta.First},
+		{"prev", meta.Prev},
+		{"next", meta.Next},
+		{"last", meta.Last},
+	} {
+		if l.url != "" {
+			links = append(links, "<"+l.url+`>; rel="`+l.rel+`"`)
+		}
+	}
+	return strings.Join(links, ", ")
+}
+
+// pageURL returns the request path and query with params replaced.
+// Empty values remove the parameter.
+func pageURL(r *http.Request, params map[string]string) string {
+	q := r.URL.Query()
+	for k, v := range params {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+
+	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+	return u.String()
+}
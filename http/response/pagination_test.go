@@ -0,0 +1,73 @@
+package response_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/http/request"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestPaginatedOffset(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users?page=2&per_page=10&sort=name", nil)
+	p := request.Page(req, request.PageLimits{})
+	rr := httptest.NewRecorder()
+
+	response.Paginated(rr, req, http.StatusOK, []string{"a", "b"}, response.OffsetPage(req, p, 35))
+
+	wantLink := `</users?page=1&per_page=10&sort=name>; rel="first", ` +
+		`</users?page=1&per_page=10&sort=name>; rel="prev", ` +
+		`</users?page=3&per_page=10&sort=name>; rel="next", ` +
+		`</users?page=4&per_page=10&sort=name>; rel="last"`
+	if link := rr.Header().Get("Link"); link != wantLink {
+		t.Errorf("got Link %q, want %q", link, wantLink)
+	}
+
+	var body struct {
+		Data []string          `json:"data"`
+		Meta response.PageMeta `json:"meta"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(body.Data) != 2 || body.Meta.Total == nil || *body.Meta.Total != 35 || body.Meta.Page != 2 {
+		t.Errorf("got body %+v", body)
+	}
+	if body.Meta.Next != "/users?page=3&per_page=10&sort=name" {
+		t.Errorf("got next %q", body.Meta.Next)
+	}
+}
+
+func TestOffsetPageBounds(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+
+	meta := response.OffsetPage(req, request.PageParams{Page: 1, PerPage: 20}, 0)
+
+	if meta.Next != "" || meta.Prev != "" {
+		t.Errorf("single page should have no neighbours, got %+v", meta)
+	}
+
+	// Hand-built params without a page size must not divide by zero.
+	meta = response.OffsetPage(req, request.PageParams{}, 42)
+	if meta.Next != "" || meta.Prev != "" || *meta.Total != 42 {
+		t.Errorf("params without a page size: got %+v", meta)
+	}
+}
+
+func TestCursorPage(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/events?cursor=old&limit=5", nil)
+
+	meta := response.CursorPage(req, 5, "abc", "")
+
+	if meta.Next != "/events?cursor=abc&limit=5" {
+		t.Errorf("got next %q", meta.Next)
+	}
+	if meta.First != "/events?limit=5" {
+		t.Errorf("got first %q", meta.First)
+	}
+	if meta.Prev != "" || meta.Total != nil {
+		t.Errorf("got %+v", meta)
+	}
+}
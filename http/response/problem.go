@@ -0,0 +1,209 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+// Problem is an RFC 9457 problem details object.
+// Extensions are serialized as top-level members alongside the standard ones.
+type Problem struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+// NewProblem creates a problem for the given status.
+// The title defaults to the standard status text.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// With sets an extension member and returns the problem.
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any)
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return fmt.Sprintf("%d %s: %s", p.Status, p.Title, p.Detail)
+	}
+	return fmt.Sprintf("%d %s", p.Status, p.Title)
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	base, err := json.Marshal((*problem)(p))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.Extensions) == 0 {
+		return base, nil
+	}
+
+	members := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	// Standard members take precedence over extensions with the same name.
+	var standard map[string]any
+	if err := json.Unmarshal(base, &standard); err != nil {
+		return nil, err
+	}
+	for k, v := range standard {
+		members[k] = v
+	}
+
+	return json.Marshal(members)
+}
+
+// Sends a problem details response.
+// A problem without a status is sent as 500 Internal Server Error, with the
+// status filled in the body as well; p itself is not modified.
+func WriteProblem(w http.ResponseWriter, p *Problem) {
+	if p.Status == 0 {
+		cp := *p
+		cp.Status = http.StatusInternalServerError
+		p = &cp
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := json.NewEncoder(buf).Encode(p); err != nil {
+		ServerError(w, fmt.Errorf("encode problem: %w", err))
+		return
+	}
+
+	w.Header().Set(ghttp.HeaderContentType, ghttp.MimeProblemJSON)
+	w.WriteHeader(p.Status)
+	_, _ = buf.WriteTo(w)
+}
+
+// Sends a 400 Bad Request problem
+func BadRequest(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusBadRequest, detail))
+}
+
+// Sends a 401 Unauthorized problem
+func Unauthorized(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusUnauthorized, detail))
+}
+
+// Sends a 403 Forbidden problem
+func Forbidden(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusForbidden, detail))
+}
+
+// Sends a 404 Not Found problem
+func NotFound(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusNotFound, detail))
+}
+
+// Sends a 405 Method Not Allowed problem
+func MethodNotAllowed(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusMethodNotAllowed, detail))
+}
+
+// Sends a 409 Conflict problem
+func Conflict(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusConflict, detail))
+}
+
+// Sends a 422 Unprocessable Entity problem
+func UnprocessableEntity(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusUnprocessableEntity, detail))
+}
+
+// Sends a 429 Too Many Requests problem
+func TooManyRequests(w http.ResponseWriter, detail string) {
+	WriteProblem(w, NewProblem(http.StatusTooManyRequests, detail))
+}
+
+// Sends the problem matching err, or a server error if err is not recognized.
+func Error(w http.ResponseWriter, err error) {
+	p, ok := ProblemFromError(err)
+	if !ok {
+		ServerError(w, err)
+		return
+	}
+	WriteProblem(w, p)
+}
+
+// ProblemFromError converts known errors into problems.
+// It reports false when err has no client-facing representation.
+func ProblemFromError(err error) (*Problem, bool) {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p, true
+	}
+
+	var validationErr request.ValidationError
+	if errors.As(err, &validationErr) {
+		return NewProblem(http.StatusUnprocessableEntity, "The request contains invalid fields.").
+			With("errors", map[string]string(validationErr)), true
+	}
+
+	var decodeErr *request.DecodeError
+	if errors.As(err, &decodeErr) {
+		return decodeProblem(decodeErr.Err), true
+	}
+
+	return nil, false
+}
+
+// decodeProblem describes a JSON decoding failure without exposing internals.
+func decodeProblem(err error) *Problem {
+	var (
+		syntaxErr    *json.SyntaxError
+		typeErr      *json.UnmarshalTypeError
+		maxBytesErr  *http.MaxBytesError
+		unknownField = "json: unknown field "
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return NewProblem(http.StatusBadRequest,
+			fmt.Sprintf("Request body contains badly-formed JSON (at position %d).", syntaxErr.Offset))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return NewProblem(http.StatusBadRequest, "Request body contains badly-formed JSON.")
+	case errors.As(err, &typeErr):
+		if typeErr.Field != "" {
+			return NewProblem(http.StatusBadRequest,
+				fmt.Sprintf("Request body contains an invalid value for the %q field.", typeErr.Field))
+		}
+		return NewProblem(http.StatusBadRequest,
+			fmt.Sprintf("Request body contains an invalid value (at position %d).", typeErr.Offset))
+	case errors.Is(err, io.EOF):
+		return NewProblem(http.StatusBadRequest, "Request body must not be empty.")
+	case strings.HasPrefix(err.Error(), unknownField):
+		field := strings.TrimPrefix(err.Error(), unknownField)
+		return NewProblem(http.StatusBadRequest,
+			fmt.Sprintf("Request body contains unknown field %s.", field))
+	case errors.As(err, &maxBytesErr):
+		return NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Request body must not be larger than %d bytes.", maxBytesErr.Limit))
+	default:
+		return NewProblem(http.StatusBadRequest, "Request body could not be decoded.")
+	}
+}
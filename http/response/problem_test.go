@@ -0,0 +1,136 @@
+package response_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/request"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestWriteProblem(t *testing.T) {
+	rr := httptest.NewRecorder()
+	p := response.NewProblem(http.StatusConflict, "Email is already taken.").With("field", "email")
+	p.Instance = "/users"
+
+	response.WriteProblem(rr, p)
+
+	if rr.Code != http.StatusConflict {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusConflict)
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeProblemJSON {
+		t.Errorf("got content type %q, want %q", ct, ghttp.MimeProblemJSON)
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal body: %v", err)
+	}
+
+	want := map[string]any{
+		"title":    "Conflict",
+		"status":   float64(http.StatusConflict),
+		"detail":   "Email is already taken.",
+		"instance": "/users",
+		"field":    "email",
+	}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("got %s=%v, want %v", k, body[k], v)
+		}
+	}
+	if _, ok := body["type"]; ok {
+		t.Errorf("type should be omitted, got %v", body["type"])
+	}
+}
+
+func TestWriteProblemWithoutStatus(t *testing.T) {
+	rr := httptest.NewRecorder()
+	p := &response.Problem{Title: "Something went wrong"}
+
+	response.WriteProblem(rr, p)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if !strings.Contains(rr.Body.String(), `"status":500`) {
+		t.Errorf("body status does not match the response: %s", rr.Body)
+	}
+	if p.Status != 0 {
+		t.Errorf("the problem was modified: got status %d", p.Status)
+	}
+}
+
+func TestProblemExtensionsDoNotOverrideStandardMembers(t *testing.T) {
+	p := response.NewProblem(http.StatusNotFound, "").With("status", 200)
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(b), `"status":404`) {
+		t.Errorf("standard status member was overridden: %s", b)
+	}
+}
+
+func TestProblemFromError(t *testing.T) {
+	decode := func(body string) error {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		_, err := request.JSON[struct {
+			Name string `json:"name"`
+		}](r)
+		return err
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		ok     bool
+		status int
+		detail string
+	}{
+		{"syntax error", decode(`{"name":`), true, http.StatusBadRequest, "badly-formed JSON"},
+		{"empty body", decode(``), true, http.StatusBadRequest, "must not be empty"},
+		{"unknown field", decode(`{"age":1}`), true, http.StatusBadRequest, `unknown field "age"`},
+		{"wrong type", decode(`{"name":1}`), true, http.StatusBadRequest, `"name" field`},
+		{"validation", request.ValidationError{"name": "is required"}, true, http.StatusUnprocessableEntity, "invalid fields"},
+		{"problem", response.NewProblem(http.StatusForbidden, "nope"), true, http.StatusForbidden, "nope"},
+		{"unknown error", errors.New("boom"), false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := response.ProblemFromError(tt.err)
+			if ok != tt.ok {
+				t.Fatalf("got ok %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if p.Status != tt.status {
+				t.Errorf("got status %d, want %d", p.Status, tt.status)
+			}
+			if !strings.Contains(p.Detail, tt.detail) {
+				t.Errorf("detail %q should contain %q", p.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestErrorWritesValidationProblem(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	response.Error(rr, request.ValidationError{"email": "is invalid"})
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusUnprocessableEntity)
+	}
+	if !strings.Contains(rr.Body.String(), `"errors":{"email":"is invalid"}`) {
+		t.Errorf("body should contain the field errors, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Redirect sends a redirect to a url on the same site.
+// Targets that would leave the site are replaced with "/" to prevent open
+// redirects. Statuses other than 301, 302, 303, 307 and 308 are replaced with
+// 303 See Other.
+func Redirect(w http.ResponseWriter, r *http.Request, target string, status int) {
+	if !IsLocalURL(target) {
+		target = "/"
+	}
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		status = http.StatusSeeOther
+	}
+
+	http.Redirect(w, r, target, status)
+}
+
+// IsLocalURL reports whether target is a path on the current host.
+func IsLocalURL(target string) bool {
+	if target == "" || target[0] != '/' {
+		return false
+	}
+
+	// Browsers treat //host and /\host as references to another host.
+	if len(target) > 1 && (target[1] == '/' || target[1] == '\\') {
+		return false
+	}
+
+	if strings.ContainsAny(target, "\r\n\t") {
+		return false
+	}
+
+	u, err := url.Parse(target)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
@@ -0,0 +1,49 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func TestRedirect(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		status     int
+		wantTarget string
+		wantStatus int
+	}{
+		{"local path", "/dashboard?tab=1", http.StatusSeeOther, "/dashboard?tab=1", http.StatusSeeOther},
+		{"absolute url", "https://evil.example/login", http.StatusFound, "/", http.StatusFound},
+		{"protocol relative", "//evil.example", http.StatusFound, "/", http.StatusFound},
+		{"backslash", "/\\evil.example", http.StatusFound, "/", http.StatusFound},
+		{"relative path", "dashboard", http.StatusFound, "/", http.StatusFound},
+		{"header injection", "/ok\r\nSet-Cookie: x=1", http.StatusFound, "/", http.StatusFound},
+		{"javascript", "javascript:alert(1)", http.StatusFound, "/", http.StatusFound},
+		{"invalid status", "/home", http.StatusOK, "/home", http.StatusSeeOther},
+		{"multiple choices", "/home", http.StatusMultipleChoices, "/home", http.StatusSeeOther},
+		{"not modified", "/home", http.StatusNotModified, "/home", http.StatusSeeOther},
+		{"use proxy", "/home", http.StatusUseProxy, "/home", http.StatusSeeOther},
+		{"temporary", "/home", http.StatusTemporaryRedirect, "/home", http.StatusTemporaryRedirect},
+		{"permanent", "/home", http.StatusPermanentRedirect, "/home", http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/form", nil)
+
+			response.Redirect(rr, req, tt.target, tt.status)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("got status %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if loc := rr.Header().Get("Location"); loc != tt.wantTarget {
+				t.Errorf("got location %q, want %q", loc, tt.wantTarget)
+			}
+		})
+	}
+}
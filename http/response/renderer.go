@@ -0,0 +1,359 @@
+package response
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/request"
+)
+
+const (
+	partialsDir   = "partials"
+	pagesDir      = "pages"
+	tmplSuffix    = ".html"
+	fragmentBlock = "content"
+)
+
+// ErrTemplateNotFound is returned when a layout or page does not exist.
+var ErrTemplateNotFound = errors.New("template not found")
+
+// RendererConfig configures a Renderer.
+type RendererConfig struct {
+	// FS holds the templates. Defaults to the templates directory on disk.
+	FS fs.FS
+
+	// Layouts are the paths of the layout files within FS.
+	// The first one is the default layout. Defaults to layout.html.
+	Layouts []string
+
+	// PartialsDir holds templates shared by every layout and page.
+	// It is skipped when it does not exist. Defaults to partials.
+	PartialsDir string
+
+	// PagesDir holds the page templates. Defaults to pages.
+	PagesDir string
+
+	// Funcs are merged into the template FuncMap.
+	Funcs template.FuncMap
+
+	// Reload parses the templates again on every render.
+	// Meant for development, when the templates are edited on disk.
+	Reload bool
+
+	// ErrorPages maps status codes to the pages rendered for them.
+	// The page under key 0 is used for statuses without a page of their own.
+	// Error pages receive an ErrorPageData.
+	ErrorPages map[int]string
+
+	// FragmentBlock is the block rendered instead of the whole layout
+	// when htmx requests a fragment. Defaults to content.
+	FragmentBlock string
+
+	// RequestFuncs provide template functions bound to the current request.
+	RequestFuncs []RequestFuncs
+
+	// Flash exposes pending flash messages to templates as flashes.
+	Flash *Flash
+}
+
+// RequestFuncs returns template functions bound to a single request.
+// It is also called with an empty request and a discarding writer when the
+// templates are parsed, to declare the functions, so it must not assume that
+// any request state is present.
+//
+// Templates are cloned on every render when request functions are configured,
+// which costs some performance in exchange for per-request values.
+type RequestFuncs func(w http.ResponseWriter, r *http.Request) template.FuncMap
+
+// ErrorPageData is the data passed to error page templates.
+type ErrorPageData struct {
+	Status int
+	Title  string
+}
+
+// Renderer renders pages against their layouts and caches the parsed templates.
+// Pages are addressed by their path within PagesDir without the .html suffix.
+type Renderer struct {
+	cfg           RendererConfig
+	defaultLayout string
+	requestFuncs  []RequestFuncs
+
+	mu      sync.RWMutex
+	layouts map[string]map[string]*template.Template
+}
+
+// Creates a Renderer and parses all of its templates
+func NewRenderer(cfg RendererConfig) (*Renderer, error) {
+	if cfg.FS == nil {
+		cfg.FS = os.DirFS(templatesDir)
+	}
+	if len(cfg.Layouts) == 0 {
+		cfg.Layouts = []string{layoutFile}
+	}
+	if cfg.PartialsDir == "" {
+		cfg.PartialsDir = partialsDir
+	}
+	if cfg.PagesDir == "" {
+		cfg.PagesDir = pagesDir
+	}
+	if cfg.FragmentBlock == "" {
+		cfg.FragmentBlock = fragmentBlock
+	}
+
+	rd := &Renderer{
+		cfg:           cfg,
+		defaultLayout: layoutName(cfg.Layouts[0]),
+		requestFuncs:  cfg.RequestFuncs,
+	}
+	if cfg.Flash != nil {
+		rd.requestFuncs = append(rd.requestFuncs, cfg.Flash.TemplateFuncs)
+	}
+
+	layouts, err := rd.parse()
+	if err != nil {
+		return nil, err
+	}
+	rd.layouts = layouts
+
+	return rd, nil
+}
+
+// Execute renders a page with the given layout into w.
+// An empty layout selects the default layout.
+func (rd *Renderer) Execute(w io.Writer, layout, page string, data any) error {
+	return rd.execute(w, nil, nil, layout, page, "", data)
+}
+
+// ExecuteBlock renders a single named template or block of a page into w,
+// without the layout around it.
+func (rd *Renderer) ExecuteBlock(w io.Writer, page, block string, data any) error {
+	return rd.execute(w, nil, nil, "", page, block, data)
+}
+
+// execute renders the named template of a page, or the whole layout when
+// name is empty. Request functions are bound to rw and r when r is set.
+func (rd *Renderer) execute(out io.Writer, rw http.ResponseWriter, r *http.Request, layout, page, name string, data any) error {
+	if layout == "" {
+		layout = rd.defaultLayout
+	}
+
+	tmpl, err := rd.lookup(layout, page)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		name = layout + tmplSuffix
+	} else if tmpl.Lookup(name) == nil {
+		return fmt.Errorf("block %s of page %s: %w", name, page, ErrTemplateNotFound)
+	}
+
+	// Cached templates are never executed directly when request functions
+	// are in use, since html/template cannot clone executed templates.
+	if len(rd.requestFuncs) > 0 {
+		tmpl, err = tmpl.Clone()
+		if err != nil {
+			return fmt.Errorf("clone template %s/%s: %w", layout, page, err)
+		}
+
+		if r != nil {
+			funcs := template.FuncMap{}
+			for _, fn := range rd.requestFuncs {
+				for k, v := range fn(rw, r) {
+					funcs[k] = v
+				}
+			}
+			tmpl.Funcs(funcs)
+		}
+	}
+
+	if err := tmpl.ExecuteTemplate(out, name, data); err != nil {
+		return fmt.Errorf("execute template %s/%s/%s: %w", layout, page, name, err)
+	}
+	return nil
+}
+
+// Render sends a page rendered with the default layout as an HTML response.
+// The page is rendered into a buffer first, so nothing is written when
+// rendering fails; the 500 error page is sent instead.
+// When htmx asks for a fragment, only the FragmentBlock of the page is sent.
+func (rd *Renderer) Render(w http.ResponseWriter, r *http.Request, status int, page string, data any) {
+	w.Header().Add(ghttp.HeaderVary, ghttp.HeaderHXRequest)
+
+	if request.WantsFragment(r) {
+		rd.RenderBlock(w, r, status, page, rd.cfg.FragmentBlock, data)
+		return
+	}
+
+	rd.render(w, r, status, page, func(buf *bytes.Buffer) error {
+		return rd.execute(buf, w, r, "", page, "", data)
+	})
+}
+
+// RenderBlock sends a single named template or block of a page as an HTML response.
+func (rd *Renderer) RenderBlock(w http.ResponseWriter, r *http.Request, status int, page, block string, data any) {
+	rd.render(w, r, status, page, func(buf *bytes.Buffer) error {
+		return rd.execute(buf, w, r, "", page, block, data)
+	})
+}
+
+// render buffers the output of execute and only then commits the response.
+func (rd *Renderer) render(w http.ResponseWriter, r *http.Request, status int, page string, execute func(*bytes.Buffer) error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := execute(buf); err != nil {
+		slog.Error("render page", "page", page, "reason", err, "method", r.Method, "path", r.URL.Path)
+		rd.RenderError(w, r, http.StatusInternalServerError)
+		return
+	}
+
+	writeHTML(w, status, buf)
+}
+
+// RenderError sends the error page configured for status.
+// It falls back to the plain status text when there is no usable error page.
+func (rd *Renderer) RenderError(w http.ResponseWriter, r *http.Request, status int) {
+	page, ok := rd.cfg.ErrorPages[status]
+	if !ok {
+		page, ok = rd.cfg.ErrorPages[0]
+	}
+
+	if ok {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		data := ErrorPageData{Status: status, Title: http.StatusText(status)}
+
+		err := rd.execute(buf, w, r, "", page, "", data)
+		if err == nil {
+			writeHTML(w, status, buf)
+			return
+		}
+		slog.Error("render error page", "page", page, "status", status, "reason", err, "method", r.Method, "path", r.URL.Path)
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+// writeHTML commits an HTML response with an already rendered body.
+func writeHTML(w http.ResponseWriter, status int, buf *bytes.Buffer) {
+	w.Header().Set(ghttp.HeaderContentType, ghttp.MimeHTMLUTF8)
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
+
+// lookup returns the parsed page set, reparsing it first in reload mode.
+func (rd *Renderer) lookup(layout, page string) (*template.Template, error) {
+	if rd.cfg.Reload {
+		layouts, err := rd.parse()
+		if err != nil {
+			return nil, err
+		}
+
+		rd.mu.Lock()
+		rd.layouts = layouts
+		rd.mu.Unlock()
+	}
+
+	rd.mu.RLock()
+	defer rd.mu.RUnlock()
+
+	pages, ok := rd.layouts[layout]
+	if !ok {
+		return nil, fmt.Errorf("layout %s: %w", layout, ErrTemplateNotFound)
+	}
+
+	tmpl, ok := pages[page]
+	if !ok {
+		return nil, fmt.Errorf("page %s: %w", page, ErrTemplateNotFound)
+	}
+
+	return tmpl, nil
+}
+
+// parse builds the page set of every layout.
+func (rd *Renderer) parse() (map[string]map[string]*template.Template, error) {
+	partials, err := templateFiles(rd.cfg.FS, rd.cfg.PartialsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	pagesFS, err := fs.Sub(rd.cfg.FS, rd.cfg.PagesDir)
+	if err != nil {
+		return nil, fmt.Errorf("pages directory: %w", err)
+	}
+
+	funcs := Funcs(rd.cfg.Funcs)
+	for _, fn := range rd.requestFuncs {
+		for k, v := range fn(discardWriter{}, &http.Request{Header: http.Header{}, URL: &url.URL{}}) {
+			funcs[k] = v
+		}
+	}
+
+	layouts := make(map[string]map[string]*template.Template, len(rd.cfg.Layouts))
+	for _, file := range rd.cfg.Layouts {
+		name := layoutName(file)
+
+		layoutTmpl, err := template.New(name).Funcs(funcs).ParseFS(rd.cfg.FS, append([]string{file}, partials...)...)
+		if err != nil {
+			return nil, fmt.Errorf("parse layout %s: %w", file, err)
+		}
+
+		pages, err := ParsePagesFS(pagesFS, layoutTmpl)
+		if err != nil {
+			return nil, err
+		}
+		layouts[name] = pages
+	}
+
+	return layouts, nil
+}
+
+// discardWriter stands in for the response while declaring request functions.
+type discardWriter struct{}
+
+func (discardWriter) Header() http.Header         { return http.Header{} }
+func (discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardWriter) WriteHeader(int)             {}
+
+// layoutName strips the directory and suffix of a layout file.
+func layoutName(file string) string {
+	return strings.TrimSuffix(path.Base(file), tmplSuffix)
+}
+
+// templateFiles lists the html files under dir, which may not exist.
+func templateFiles(fsys fs.FS, dir string) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+			return err
+		}
+
+		if !d.IsDir() && strings.HasSuffix(p, tmplSuffix) {
+			files = append(files, p)
+		}
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("list templates in %s: %w", dir, err)
+	}
+
+	slog.Debug("found templates", "dir", dir, "count", len(files))
+	return files, nil
+}
@@ -0,0 +1,170 @@
+package response_test
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+func newTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layout.html":           {Data: []byte(`<main>{{template "content" .}}</main>{{template "footer"}}`)},
+		"layouts/admin.html":    {Data: []byte(`<admin>{{template "content" .}}</admin>`)},
+		"partials/footer.html":  {Data: []byte(`{{define "footer"}}<footer>{{shout "bye"}}</footer>{{end}}`)},
+		"pages/home.html":       {Data: []byte(`{{define "content"}}Hello {{.}}{{end}}`)},
+		"pages/users/show.html": {Data: []byte(`{{define "content"}}User {{.}}{{end}}`)},
+	}
+}
+
+func newTestRenderer(t *testing.T, fsys fstest.MapFS, reload bool) *response.Renderer {
+	t.Helper()
+
+	rd, err := response.NewRenderer(response.RendererConfig{
+		FS:      fsys,
+		Layouts: []string{"layout.html", "layouts/admin.html"},
+		Funcs: template.FuncMap{
+			"shout": strings.ToUpper,
+		},
+		Reload: reload,
+	})
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+	return rd
+}
+
+func TestRendererExecute(t *testing.T) {
+	rd := newTestRenderer(t, newTestFS(), false)
+
+	tests := []struct {
+		layout, page, want string
+	}{
+		{"", "home", "<main>Hello Gopher</main><footer>BYE</footer>"},
+		{"layout", "users/show", "<main>User Gopher</main><footer>BYE</footer>"},
+		{"admin", "home", "<admin>Hello Gopher</admin>"},
+	}
+
+	for _, tt := range tests {
+		var sb strings.Builder
+		if err := rd.Execute(&sb, tt.layout, tt.page, "Gopher"); err != nil {
+			t.Fatalf("execute %s/%s: %v", tt.layout, tt.page, err)
+		}
+		if sb.String() != tt.want {
+			t.Errorf("got %q, want %q", sb.String(), tt.want)
+		}
+	}
+}
+
+func TestRendererMissingTemplate(t *testing.T) {
+	rd := newTestRenderer(t, newTestFS(), false)
+
+	err := rd.Execute(&strings.Builder{}, "", "missing", nil)
+	if !errors.Is(err, response.ErrTemplateNotFound) {
+		t.Errorf("got error %v, want ErrTemplateNotFound", err)
+	}
+
+	err = rd.Execute(&strings.Builder{}, "missing", "home", nil)
+	if !errors.Is(err, response.ErrTemplateNotFound) {
+		t.Errorf("got error %v, want ErrTemplateNotFound", err)
+	}
+}
+
+func TestRendererReload(t *testing.T) {
+	for _, reload := range []bool{false, true} {
+		fsys := newTestFS()
+		rd := newTestRenderer(t, fsys, reload)
+
+		fsys["pages/home.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}Changed{{end}}`)}
+
+		var sb strings.Builder
+		if err := rd.Execute(&sb, "admin", "home", nil); err != nil {
+			t.Fatalf("execute: %v", err)
+		}
+
+		changed := strings.Contains(sb.String(), "Changed")
+		if changed != reload {
+			t.Errorf("reload=%v: got %q", reload, sb.String())
+		}
+	}
+}
+
+func TestRendererRender(t *testing.T) {
+	rd := newTestRenderer(t, newTestFS(), false)
+	rr := httptest.NewRecorder()
+
+	rd.Render(rr, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusCreated, "home", "<b>")
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusCreated)
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeHTMLUTF8 {
+		t.Errorf("got content type %q, want %q", ct, ghttp.MimeHTMLUTF8)
+	}
+	if !strings.Contains(rr.Body.String(), "Hello &lt;b&gt;") {
+		t.Errorf("data should be escaped, got %q", rr.Body.String())
+	}
+}
+
+func TestRendererRenderFailure(t *testing.T) {
+	fsys := newTestFS()
+	fsys["pages/broken.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}{{.Missing}}{{end}}`)}
+	fsys["pages/errors/500.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}Oops {{.Status}}{{end}}`)}
+	fsys["pages/errors/default.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}{{.Title}}{{end}}`)}
+
+	rd, err := response.NewRenderer(response.RendererConfig{
+		FS:    fsys,
+		Funcs: template.FuncMap{"shout": strings.ToUpper},
+		ErrorPages: map[int]string{
+			http.StatusInternalServerError: "errors/500",
+			0:                              "errors/default",
+		},
+	})
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	rr := httptest.NewRecorder()
+	rd.Render(rr, req, http.StatusOK, "broken", 42)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if body := rr.Body.String(); body != "<main>Oops 500</main><footer>BYE</footer>" {
+		t.Errorf("got body %q", body)
+	}
+
+	rr = httptest.NewRecorder()
+	rd.RenderError(rr, req, http.StatusNotFound)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusNotFound)
+	}
+	if !strings.Contains(rr.Body.String(), "Not Found") {
+		t.Errorf("got body %q", rr.Body.String())
+	}
+}
+
+func TestRendererRenderFailureWithoutErrorPage(t *testing.T) {
+	fsys := newTestFS()
+	fsys["pages/broken.html"] = &fstest.MapFile{Data: []byte(`{{define "content"}}{{.Missing}}{{end}}`)}
+	rd := newTestRenderer(t, fsys, false)
+
+	rr := httptest.NewRecorder()
+	rd.Render(rr, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, "broken", 42)
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if body := rr.Body.String(); body != "Internal Server Error\n" {
+		t.Errorf("template internals should not leak, got %q", body)
+	}
+}
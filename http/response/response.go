@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"sync"
 
 	ghttp "github.com/ferdiebergado/gopherkit/http"
 )
@@ -21,14 +22,77 @@ const (
 	layoutFile   = "layout.html"
 )
 
+// maxPooledBuffer is the capacity above which buffers are not reused,
+// so a single large response does not stay in memory.
+const maxPooledBuffer = 64 << 10
+
+var bufPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufPool.Put(buf)
+}
+
+// JSONOptions configures how a JSON response is encoded.
+type JSONOptions struct {
+	// Indent pretty-prints the body using the given indentation.
+	Indent string
+
+	// EscapeHTML escapes <, > and & inside strings.
+	EscapeHTML bool
+
+	// Envelope wraps the value as {"data":...,"meta":...}.
+	Envelope bool
+
+	// Meta is sent next to the data when Envelope is set.
+	Meta any
+}
+
+// Envelope is the wrapper used when JSONOptions.Envelope is set.
+type Envelope struct {
+	Data any `json:"data"`
+	Meta any `json:"meta,omitempty"`
+}
+
 // Sends a JSON response
 func JSON(w http.ResponseWriter, status int, v any) {
-	w.Header().Set(ghttp.HeaderContentType, ghttp.MimeJSON)
-	w.WriteHeader(status)
+	JSONWith(w, status, v, JSONOptions{EscapeHTML: true})
+}
 
-	if err := json.NewEncoder(w).Encode(v); err != nil {
+// JSONWith sends a JSON response encoded according to opts.
+// The body is encoded before anything is written, so an encoding failure
+// results in a clean server error.
+func JSONWith(w http.ResponseWriter, status int, v any, opts JSONOptions) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if opts.Envelope {
+		v = Envelope{Data: v, Meta: opts.Meta}
+	}
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(opts.EscapeHTML)
+	enc.SetIndent("", opts.Indent)
+
+	if err := enc.Encode(v); err != nil {
 		ServerError(w, fmt.Errorf("encode json: %w", err))
+		return
 	}
+
+	w.Header().Set(ghttp.HeaderContentType, ghttp.MimeJSON)
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
 }
 
 func ServerError(w http.ResponseWriter, err error) {
@@ -36,76 +100,71 @@ func ServerError(w http.ResponseWriter, err error) {
 	http.Error(w, "An error occurred.", http.StatusInternalServerError)
 }
 
-// Sends an HTML response
+// Sends an HTML response.
+// The templates are parsed from disk on every call; use a Renderer to cache them.
+// Use Flash.HTML to expose flash messages to the layout.
 func HTML(w http.ResponseWriter, data any, templateFiles ...string) {
+	HTMLFuncs(w, nil, data, templateFiles...)
+}
+
+// HTMLFuncs is like HTML but merges funcs into the template FuncMap.
+func HTMLFuncs(w http.ResponseWriter, funcs template.FuncMap, data any, templateFiles ...string) {
 	layoutTemplate := filepath.Join(templatesDir, layoutFile)
 	targetTemplates := []string{layoutTemplate}
 
 	targetTemplates = append(targetTemplates, templateFiles...)
 
-	funcMap := templateFuncs()
+	funcMap := Funcs(funcs)
 
 	templates, err := template.New("template").Funcs(funcMap).ParseFiles(targetTemplates...)
 
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	var buf bytes.Buffer
-
-	if err := templates.ExecuteTemplate(&buf, layoutFile, data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		ServerError(w, fmt.Errorf("parse templates: %w", err))
 		return
 	}
 
-	_, err = buf.WriteTo(w)
+	buf := getBuffer()
+	defer putBuffer(buf)
 
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := templates.ExecuteTemplate(buf, layoutFile, data); err != nil {
+		ServerError(w, fmt.Errorf("execute template: %w", err))
 		return
 	}
-}
 
-// Creates a template funcmap
-func templateFuncs() template.FuncMap {
-	return template.FuncMap{
-		"attr": func(s string) template.HTMLAttr {
-			return template.HTMLAttr(s)
-		},
-		"safe": func(s string) template.HTML {
-			return template.HTML(s)
-		},
-		"url": func(s string) template.URL {
-			return template.URL(s)
-		},
-		"js": func(s string) template.JS {
-			return template.JS(s)
-		},
-		"jsstr": func(s string) template.JSStr {
-			return template.JSStr(s)
-		},
-		"css": func(s string) template.CSS {
-			return template.CSS(s)
-		},
-	}
+	writeHTML(w, http.StatusOK, buf)
 }
 
 // ParsePages recursively parses a given directory containing html templates.
-// Each page is parsed against the layout template.
+// Each page is parsed against the layout template, which should be created
+// with Funcs to make the built-in template functions available.
 // It returns a map containing the name of the template as key and the parsed template as the value.
 func ParsePages(templateDir string, layoutTmpl *template.Template) (map[string]*template.Template, error) {
+	return ParsePagesFS(os.DirFS(templateDir), layoutTmpl)
+}
+
+// ParsePagesFS is like ParsePages but reads the pages from fsys, such as an embed.FS.
+func ParsePagesFS(fsys fs.FS, layoutTmpl *template.Template) (map[string]*template.Template, error) {
 	tmplMap := make(map[string]*template.Template)
-	err := fs.WalkDir(os.DirFS(templateDir), ".", func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		const suffix = ".html"
-		if !d.IsDir() && strings.HasSuffix(path, suffix) {
+		if !d.IsDir() && strings.HasSuffix(path, tmplSuffix) {
 			name := strings.TrimPrefix(path, "/")
-			name = strings.TrimSuffix(name, suffix)
-			tmplMap[name] = template.Must(template.Must(layoutTmpl.Clone()).ParseFiles(filepath.Join(templateDir, path)))
+			name = strings.TrimSuffix(name, tmplSuffix)
+
+			layout, err := layoutTmpl.Clone()
+			if err != nil {
+				return fmt.Errorf("clone layout: %w", err)
+			}
+
+			tmpl, err := layout.ParseFS(fsys, path)
+			if err != nil {
+				return fmt.Errorf("parse page %s: %w", path, err)
+			}
+
+			tmplMap[name] = tmpl
 			slog.Debug("parsed page", "path", path, "name", name)
 		}
 		return nil
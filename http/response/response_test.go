@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	ghttp "github.com/ferdiebergado/gopherkit/http"
 	"github.com/ferdiebergado/gopherkit/http/response"
 )
 
@@ -60,3 +61,87 @@ func TestServerError(t *testing.T) {
 		t.Errorf("log output should contain the function name, got: %q", logOutput)
 	}
 }
+
+func TestJSON(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	response.JSON(rr, http.StatusCreated, map[string]string{"html": "<b>"})
+
+	if rr.Code != http.StatusCreated {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusCreated)
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeJSON {
+		t.Errorf("got content type %q, want %q", ct, ghttp.MimeJSON)
+	}
+	expected := "{\"html\":\"\\u003cb\\u003e\"}\n"
+	if rr.Body.String() != expected {
+		t.Errorf("got body %q want %q", rr.Body.String(), expected)
+	}
+}
+
+func TestJSONEncodeFailure(t *testing.T) {
+	var buf bytes.Buffer
+	oldHandler := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(oldHandler)
+
+	rr := httptest.NewRecorder()
+
+	response.JSON(rr, http.StatusOK, map[string]any{"ch": make(chan int)})
+
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusInternalServerError)
+	}
+	if rr.Body.String() != "An error occurred.\n" {
+		t.Errorf("response should only contain the server error, got %q", rr.Body.String())
+	}
+	if ct := rr.Header().Get(ghttp.HeaderContentType); strings.HasPrefix(ct, ghttp.MimeJSON) {
+		t.Errorf("failed response should not be JSON, got %q", ct)
+	}
+}
+
+func TestJSONWith(t *testing.T) {
+	tests := []struct {
+		name string
+		opts response.JSONOptions
+		v    any
+		want string
+	}{
+		{
+			name: "pretty",
+			opts: response.JSONOptions{Indent: "  "},
+			v:    map[string]int{"a": 1},
+			want: "{\n  \"a\": 1\n}\n",
+		},
+		{
+			name: "no html escaping",
+			opts: response.JSONOptions{},
+			v:    "<b>",
+			want: "\"<b>\"\n",
+		},
+		{
+			name: "envelope",
+			opts: response.JSONOptions{Envelope: true, Meta: map[string]int{"total": 2}},
+			v:    []int{1, 2},
+			want: "{\"data\":[1,2],\"meta\":{\"total\":2}}\n",
+		},
+		{
+			name: "envelope without meta",
+			opts: response.JSONOptions{Envelope: true},
+			v:    []int{},
+			want: "{\"data\":[]}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+
+			response.JSONWith(rr, http.StatusOK, tt.v, tt.opts)
+
+			if rr.Body.String() != tt.want {
+				t.Errorf("got %q, want %q", rr.Body.String(), tt.want)
+			}
+		})
+	}
+}
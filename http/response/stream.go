@@ -0,0 +1,247 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+const (
+	defaultFlushEvery    = 100
+	defaultFlushInterval = time.Second
+
+	// DefaultStreamWriteTimeout bounds every write of a stream.
+	DefaultStreamWriteTimeout = 10 * time.Second
+)
+
+// StreamFormat selects how streamed items are framed.
+type StreamFormat int
+
+const (
+	// StreamArray writes the items as a single JSON array.
+	StreamArray StreamFormat = iota
+	// StreamNDJSON writes one JSON document per line.
+	StreamNDJSON
+)
+
+// StreamOptions configures a JSON stream.
+type StreamOptions struct {
+	Format StreamFormat
+
+	// FlushEvery flushes after this many items. Defaults to 100.
+	FlushEvery int
+
+	// FlushInterval flushes when this much time passed since the last flush.
+	// Defaults to one second.
+	FlushInterval time.Duration
+
+	// WriteTimeout bounds every write, so a client that stops reading is
+	// dropped. Defaults to DefaultStreamWriteTimeout.
+	WriteTimeout time.Duration
+}
+
+// Seq yields items until it runs out or yield returns false.
+// It has the same shape as iter.Seq.
+type Seq[T any] func(yield func(T) bool)
+
+// JSONStream sends the items of seq as they are produced.
+// It stops when the request context is canceled. Since the headers are sent
+// before the first item, failures are logged rather than sent to the client.
+// The server's deadlines are managed with ExtendDeadlines, so long streams
+// are not cut off by the timeouts meant for regular requests.
+func JSONStream[T any](w http.ResponseWriter, r *http.Request, status int, seq Seq[T], opts StreamOptions) {
+	s := newJSONStreamer(w, r, opts)
+	s.start(status)
+
+	seq(func(v T) bool {
+		return s.write(v)
+	})
+
+	s.finish()
+}
+
+// JSONStreamChan sends the items received from ch until it is closed.
+// Pending items are flushed every FlushInterval even while ch is idle, so a
+// slow producer does not leave them sitting in the buffer.
+func JSONStreamChan[T any](w http.ResponseWriter, r *http.Request, status int, ch <-chan T, opts StreamOptions) {
+	s := newJSONStreamer(w, r, opts)
+	s.start(status)
+
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-r.Context().Done():
+			break loop
+		case v, ok := <-ch:
+			if !ok || !s.write(v) {
+				break loop
+			}
+		case <-ticker.C:
+			if s.pending > 0 {
+				s.flush()
+			}
+			if s.err != nil {
+				break loop
+			}
+		}
+	}
+
+	s.finish()
+}
+
+// jsonStreamer frames and flushes the items of a JSON stream.
+type jsonStreamer struct {
+	w    http.ResponseWriter
+	r    *http.Request
+	rc   *http.ResponseController
+	opts StreamOptions
+	enc  *json.Encoder
+
+	count     int
+	pending   int
+	lastFlush time.Time
+	err       error
+}
+
+func newJSONStreamer(w http.ResponseWriter, r *http.Request, opts StreamOptions) *jsonStreamer {
+	if opts.FlushEvery <= 0 {
+		opts.FlushEvery = defaultFlushEvery
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultFlushInterval
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultStreamWriteTimeout
+	}
+
+	return &jsonStreamer{
+		w:    w,
+		r:    r,
+		rc:   http.NewResponseController(w),
+		opts: opts,
+		enc:  json.NewEncoder(w),
+	}
+}
+
+func (s *jsonStreamer) start(status int) {
+	s.extendDeadlines()
+
+	contentType := ghttp.MimeJSON
+	if s.opts.Format == StreamNDJSON {
+		contentType = ghttp.MimeNDJSON
+	}
+
+	s.w.Header().Set(ghttp.HeaderContentType, contentType)
+	s.w.WriteHeader(status)
+
+	if s.opts.Format == StreamArray {
+		s.writeRaw("[")
+	}
+	s.flush()
+}
+
+// write sends one item and reports whether streaming should continue.
+func (s *jsonStreamer) write(v any) bool {
+	if s.err != nil {
+		return false
+	}
+	if err := s.r.Context().Err(); err != nil {
+		s.err = err
+		return false
+	}
+
+	if s.opts.Format == StreamArray && s.count > 0 {
+		if !s.writeRaw(",") {
+			return false
+		}
+	}
+
+	s.extendDeadlines()
+	if s.err != nil {
+		return false
+	}
+
+	// The encoder terminates every value with a newline, which frames NDJSON
+	// and is insignificant whitespace inside an array.
+	if err := s.enc.Encode(v); err != nil {
+		s.err = fmt.Errorf("encode item %d: %w", s.count, err)
+		return false
+	}
+
+	s.count++
+	s.pending++
+	if s.pending >= s.opts.FlushEvery || time.Since(s.lastFlush) >= s.opts.FlushInterval {
+		s.flush()
+	}
+
+	return s.err == nil
+}
+
+func (s *jsonStreamer) finish() {
+	if s.err == nil && s.opts.Format == StreamArray {
+		s.writeRaw("]\n")
+	}
+	if s.err == nil {
+		s.flush()
+	}
+
+	if s.err != nil {
+		attrs := []any{"reason", s.err, "items", s.count, "method", s.r.Method, "path", s.r.URL.Path}
+		if s.r.Context().Err() != nil {
+			slog.Info("json stream aborted by client", attrs...)
+		} else {
+			slog.Error("json stream failed", attrs...)
+		}
+	}
+}
+
+func (s *jsonStreamer) writeRaw(str string) bool {
+	if _, err := s.w.Write([]byte(str)); err != nil {
+		s.err = fmt.Errorf("write stream: %w", err)
+		return false
+	}
+	return true
+}
+
+func (s *jsonStreamer) extendDeadlines() {
+	if err := ExtendDeadlines(s.rc, s.opts.WriteTimeout); err != nil {
+		s.err = err
+	}
+}
+
+func (s *jsonStreamer) flush() {
+	s.pending = 0
+	s.lastFlush = time.Now()
+
+	s.extendDeadlines()
+	if s.err != nil {
+		return
+	}
+
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.err = fmt.Errorf("flush stream: %w", err)
+	}
+}
+
+// ExtendDeadlines prepares the connection of a long-lived response for its
+// next write. The read deadline is cleared, since the request was read long
+// ago, and the write deadline is pushed writeTimeout into the future, so the
+// stream outlives the server's WriteTimeout while a client that stops reading
+// is still dropped. Writers without deadlines are left alone.
+func ExtendDeadlines(rc *http.ResponseController, writeTimeout time.Duration) error {
+	if err := rc.SetReadDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("clear read deadline: %w", err)
+	}
+	if err := rc.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("extend write deadline: %w", err)
+	}
+	return nil
+}
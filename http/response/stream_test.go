@@ -0,0 +1,239 @@
+package response_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/response"
+	"github.com/ferdiebergado/gopherkit/http/server"
+)
+
+type item struct {
+	ID int `json:"id"`
+}
+
+func items(n int) response.Seq[item] {
+	return func(yield func(item) bool) {
+		for i := 1; i <= n; i++ {
+			if !yield(item{ID: i}) {
+				return
+			}
+		}
+	}
+}
+
+func TestJSONStreamArray(t *testing.T) {
+	for _, n := range []int{0, 1, 3} {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/export", nil)
+
+		response.JSONStream(rr, req, http.StatusOK, items(n), response.StreamOptions{FlushEvery: 2})
+
+		if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeJSON {
+			t.Errorf("got content type %q, want %q", ct, ghttp.MimeJSON)
+		}
+
+		var got []item
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("n=%d: invalid JSON array %q: %v", n, rr.Body.String(), err)
+		}
+		if len(got) != n {
+			t.Errorf("got %d items, want %d", len(got), n)
+		}
+		if !rr.Flushed {
+			t.Errorf("n=%d: response should be flushed", n)
+		}
+	}
+}
+
+func TestJSONStreamNDJSON(t *testing.T) {
+	ch := make(chan item, 3)
+	for i := 1; i <= 3; i++ {
+		ch <- item{ID: i}
+	}
+	close(ch)
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+
+	response.JSONStreamChan(rr, req, http.StatusOK, ch, response.StreamOptions{Format: response.StreamNDJSON})
+
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeNDJSON {
+		t.Errorf("got content type %q, want %q", ct, ghttp.MimeNDJSON)
+	}
+
+	want := "{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n"
+	if rr.Body.String() != want {
+		t.Errorf("got %q, want %q", rr.Body.String(), want)
+	}
+}
+
+func TestJSONStreamClientDisconnect(t *testing.T) {
+	var buf bytes.Buffer
+	oldHandler := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(oldHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/export", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+
+	seq := func(yield func(item) bool) {
+		for i := 1; ; i++ {
+			if i == 3 {
+				cancel()
+			}
+			if !yield(item{ID: i}) {
+				return
+			}
+		}
+	}
+
+	response.JSONStream(rr, req, http.StatusOK, seq, response.StreamOptions{})
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", rr.Code, http.StatusOK)
+	}
+	if strings.Contains(rr.Body.String(), "An error occurred.") {
+		t.Errorf("server error should not be written after headers: %q", rr.Body.String())
+	}
+	if !strings.Contains(buf.String(), "json stream aborted by client") {
+		t.Errorf("disconnect should be logged, got %q", buf.String())
+	}
+}
+
+func TestJSONStreamOutlivesServerTimeouts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.JSONStream(w, r, http.StatusOK, func(yield func(item) bool) {
+			for i := 1; i <= 6; i++ {
+				time.Sleep(50 * time.Millisecond)
+				if !yield(item{ID: i}) {
+					return
+				}
+			}
+		}, response.StreamOptions{Format: response.StreamNDJSON})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = server.Run(ctx, handler, server.Options{
+			Listener:     ln,
+			ReadTimeout:  100 * time.Millisecond,
+			WriteTimeout: 100 * time.Millisecond,
+			Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		})
+	}()
+
+	res, err := http.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("read stream: %v", err)
+	}
+	if lines := strings.Count(string(body), "\n"); lines != 6 {
+		t.Errorf("got %d items, want 6:\n%s", lines, body)
+	}
+}
+
+func TestJSONStreamDropsStalledClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		big := strings.Repeat("x", 64<<10)
+		response.JSONStream(w, r, http.StatusOK, func(yield func(string) bool) {
+			for yield(big) {
+			}
+		}, response.StreamOptions{Format: response.StreamNDJSON, WriteTimeout: 100 * time.Millisecond})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = server.Run(ctx, handler, server.Options{
+			Listener: ln,
+			Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		})
+	}()
+
+	// The client sends a request and never reads the response.
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream kept writing to a client that stopped reading")
+	}
+}
+
+func TestJSONStreamChanFlushesWhileIdle(t *testing.T) {
+	ch := make(chan item)
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		go func() {
+			defer close(ch)
+			ch <- item{ID: 1}
+			<-release
+		}()
+		response.JSONStreamChan(w, r, http.StatusOK, ch, response.StreamOptions{
+			Format:        response.StreamNDJSON,
+			FlushInterval: 20 * time.Millisecond,
+		})
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	res, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer res.Body.Close()
+
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(res.Body).ReadString('\n')
+		line <- s
+	}()
+
+	select {
+	case got := <-line:
+		if got != `{"id":1}`+"\n" {
+			t.Errorf("got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the first item was not flushed while the producer was idle")
+	}
+}
@@ -0,0 +1,336 @@
+// Package router adds route groups, middleware, named routes and
+// 405 handling on top of http.ServeMux.
+package router
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+// Methods tried when looking for the methods a path allows
+var methods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// Route is a registered route.
+type Route struct {
+	// Method is "" for routes matching every method.
+	Method string
+
+	// Path is the full ServeMux path pattern, including group prefixes.
+	Path string
+
+	Name string
+
+	table *table
+}
+
+// Named names the route for URL reversal. Names must be unique.
+func (rt *Route) Named(name string) *Route {
+	rt.table.mu.Lock()
+	defer rt.table.mu.Unlock()
+
+	if _, ok := rt.table.names[name]; ok {
+		panic(fmt.Sprintf("router: route name %q is already used", name))
+	}
+	rt.Name = name
+	rt.table.names[name] = rt
+	return rt
+}
+
+// table is shared by a router and its groups.
+type table struct {
+	mux *http.ServeMux
+
+	mu     sync.RWMutex
+	routes []*Route
+	names  map[string]*Route
+
+	notFound         http.Handler
+	methodNotAllowed http.Handler
+
+	// root is the router created by New, whose middleware also wraps the
+	// 404 and 405 responses.
+	root *Router
+}
+
+// Router registers routes on a ServeMux. Groups share the routes of the
+// router they were created from and add a prefix and middleware of their own.
+type Router struct {
+	table  *table
+	parent *Router
+	prefix string
+	chain  middleware.Chain
+
+	// hasRoutes is set once a route was registered on the router or any of
+	// its groups.
+	hasRoutes bool
+}
+
+// Creates a Router
+func New() *Router {
+	r := &Router{table: &table{
+		mux:   http.NewServeMux(),
+		names: make(map[string]*Route),
+	}}
+	r.table.root = r
+	return r
+}
+
+// Use adds middleware to the routes registered on this router and its groups
+// from now on. It panics once routes were registered on the router or its
+// groups, since those would silently miss the middleware.
+func (r *Router) Use(mws ...middleware.Middleware) {
+	if r.hasRoutes {
+		panic("router: middleware must be added before routes")
+	}
+	r.chain = r.chain.Append(mws...)
+}
+
+// Group creates a group under prefix that inherits the middleware of r,
+// and passes it to fn when fn is not nil.
+func (r *Router) Group(prefix string, fn func(g *Router)) *Router {
+	g := &Router{
+		table:  r.table,
+		parent: r,
+		prefix: r.prefix + strings.TrimSuffix(prefix, "/"),
+		chain:  r.chain.Append(),
+	}
+	if fn != nil {
+		fn(g)
+	}
+	return g
+}
+
+// Handle registers h for method and path, which follow the ServeMux pattern
+// syntax; an empty method matches every method. In a group, an empty path
+// stands for the prefix itself.
+func (r *Router) Handle(method, path string, h http.Handler) *Route {
+	for g := r; g != nil; g = g.parent {
+		g.hasRoutes = true
+	}
+
+	full := r.prefix + path
+	if full == "" {
+		full = "/"
+	}
+
+	pattern := full
+	if method != "" {
+		pattern = method + " " + full
+	}
+	r.table.mux.Handle(pattern, r.chain.Then(h))
+
+	route := &Route{Method: method, Path: full, table: r.table}
+	r.table.mu.Lock()
+	r.table.routes = append(r.table.routes, route)
+	r.table.mu.Unlock()
+
+	return route
+}
+
+// HandleFunc registers fn for method and path.
+func (r *Router) HandleFunc(method, path string, fn func(http.ResponseWriter, *http.Request)) *Route {
+	return r.Handle(method, path, http.HandlerFunc(fn))
+}
+
+// Get registers h for GET, which also serves HEAD.
+func (r *Router) Get(path string, h http.Handler) *Route {
+	return r.Handle(http.MethodGet, path, h)
+}
+
+// Post registers h for POST.
+func (r *Router) Post(path string, h http.Handler) *Route {
+	return r.Handle(http.MethodPost, path, h)
+}
+
+// Put registers h for PUT.
+func (r *Router) Put(path string, h http.Handler) *Route {
+	return r.Handle(http.MethodPut, path, h)
+}
+
+// Patch registers h for PATCH.
+func (r *Router) Patch(path string, h http.Handler) *Route {
+	return r.Handle(http.MethodPatch, path, h)
+}
+
+// Delete registers h for DELETE.
+func (r *Router) Delete(path string, h http.Handler) *Route {
+	return r.Handle(http.MethodDelete, path, h)
+}
+
+// NotFound replaces the 404 problem sent for unknown paths.
+func (r *Router) NotFound(h http.Handler) {
+	r.table.notFound = h
+}
+
+// MethodNotAllowed replaces the 405 problem sent when a path exists but not
+// for the request method. The Allow header is set before h is called.
+func (r *Router) MethodNotAllowed(h http.Handler) {
+	r.table.methodNotAllowed = h
+}
+
+// ServeHTTP dispatches the request to the matching route.
+// Requests without a route still pass through the middleware of the root
+// router, so CORS preflights, logging and metrics also cover 404 and 405
+// responses; group middleware only runs for the routes of the group.
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	t := r.table
+	if _, pattern := t.mux.Handler(req); pattern != "" {
+		t.mux.ServeHTTP(w, req)
+		return
+	}
+
+	t.root.chain.Then(http.HandlerFunc(t.fallback)).ServeHTTP(w, req)
+}
+
+// fallback answers requests that match no route.
+func (t *table) fallback(w http.ResponseWriter, req *http.Request) {
+	if allowed := t.allowed(req); len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if t.methodNotAllowed != nil {
+			t.methodNotAllowed.ServeHTTP(w, req)
+			return
+		}
+		response.MethodNotAllowed(w, fmt.Sprintf("The %s method is not allowed for this resource.", req.Method))
+		return
+	}
+
+	if t.notFound != nil {
+		t.notFound.ServeHTTP(w, req)
+		return
+	}
+	response.NotFound(w, "The requested resource was not found.")
+}
+
+// allowed returns the methods that have a route for the path of req.
+func (t *table) allowed(req *http.Request) []string {
+	var allowed []string
+	probe := req.Clone(req.Context())
+	for _, method := range methods {
+		probe.Method = method
+		if _, pattern := t.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// Pattern returns the pattern of the route matching req, or "".
+// It fits middleware.AccessLogOptions.RoutePattern and
+// middleware.MetricsOptions.RoutePattern.
+func (r *Router) Pattern(req *http.Request) string {
+	_, pattern := r.table.mux.Handler(req)
+	return pattern
+}
+
+// Routes returns the registered routes sorted by path and method.
+func (r *Router) Routes() []Route {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	routes := make([]Route, len(r.table.routes))
+	for i, rt := range r.table.routes {
+		routes[i] = Route{Method: rt.Method, Path: rt.Path, Name: rt.Name}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// URL builds the path of a named route from pairs of parameter names and
+// values. Values fill the wildcards of the path; parameters without a
+// wildcard are added to the query string.
+//
+//	r.URL("users.show", "id", 42, "tab", "posts") // /users/42?tab=posts
+func (r *Router) URL(name string, pairs ...any) (string, error) {
+	r.table.mu.RLock()
+	route, ok := r.table.names[name]
+	r.table.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("router: no route named %q", name)
+	}
+
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("router: %s: odd number of parameters", name)
+	}
+	params := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("router: %s: parameter name %v is not a string", name, pairs[i])
+		}
+		params[key] = fmt.Sprint(pairs[i+1])
+	}
+
+	path, err := fillPath(route.Path, params)
+	if err != nil {
+		return "", fmt.Errorf("router: %s: %w", name, err)
+	}
+
+	if len(params) > 0 {
+		query := url.Values{}
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		path += "?" + query.Encode()
+	}
+	return path, nil
+}
+
+// TemplateFuncs exposes URL to templates as urlFor:
+//
+//	<a href="{{urlFor "users.show" "id" .ID}}">
+func (r *Router) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"urlFor": r.URL,
+	}
+}
+
+// fillPath replaces the wildcards of a path pattern with params,
+// removing the params it uses.
+func fillPath(pattern string, params map[string]string) (string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") || !strings.HasSuffix(seg, "}") {
+			continue
+		}
+
+		name := seg[1 : len(seg)-1]
+		if name == "$" {
+			segments[i] = ""
+			continue
+		}
+
+		name, rest := strings.CutSuffix(name, "...")
+		value, ok := params[name]
+		if !ok {
+			return "", fmt.Errorf("missing parameter %q", name)
+		}
+		delete(params, name)
+
+		if rest {
+			parts := strings.Split(value, "/")
+			for j, p := range parts {
+				parts[j] = url.PathEscape(p)
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
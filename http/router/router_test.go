@@ -0,0 +1,278 @@
+package router_test
+
+import (
+	"html/template"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/middleware"
+	"github.com/ferdiebergado/gopherkit/http/router"
+)
+
+func write(s string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, s+" "+r.PathValue("id"))
+	})
+}
+
+func tag(name string) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Tags", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func newTestRouter() *router.Router {
+	r := router.New()
+	r.Use(tag("root"))
+
+	r.Get("/{$}", write("home")).Named("home")
+
+	r.Group("/api", func(api *router.Router) {
+		api.Use(tag("api"))
+		api.Get("/users", write("list users")).Named("users.index")
+		api.Post("/users", write("create user"))
+		api.Get("/users/{id}", write("show user")).Named("users.show")
+		api.Delete("/users/{id}", write("delete user"))
+
+		api.Group("/admin", func(admin *router.Router) {
+			admin.Use(tag("admin"))
+			admin.Get("", write("admin"))
+			admin.Get("/files/{path...}", write("file")).Named("admin.files")
+		})
+	})
+
+	return r
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(method, target, nil))
+	return rr
+}
+
+func TestRouterGroups(t *testing.T) {
+	r := newTestRouter()
+
+	tests := []struct {
+		method, target string
+		body, tags     string
+	}{
+		{http.MethodGet, "/", "home ", "root"},
+		{http.MethodGet, "/api/users", "list users ", "root,api"},
+		{http.MethodPost, "/api/users", "create user ", "root,api"},
+		{http.MethodGet, "/api/users/7", "show user 7", "root,api"},
+		{http.MethodDelete, "/api/users/7", "delete user 7", "root,api"},
+		{http.MethodGet, "/api/admin", "admin ", "root,api,admin"},
+		{http.MethodGet, "/api/admin/files/a/b.txt", "file ", "root,api,admin"},
+	}
+
+	for _, tt := range tests {
+		rr := serve(r, tt.method, tt.target)
+		if rr.Code != http.StatusOK || rr.Body.String() != tt.body {
+			t.Errorf("%s %s: got %d %q, want %q", tt.method, tt.target, rr.Code, rr.Body.String(), tt.body)
+		}
+		if got := strings.Join(rr.Header().Values("X-Tags"), ","); got != tt.tags {
+			t.Errorf("%s %s: got middleware %q, want %q", tt.method, tt.target, got, tt.tags)
+		}
+	}
+}
+
+func TestRouterNotAllowedAndNotFound(t *testing.T) {
+	r := newTestRouter()
+
+	rr := serve(r, http.MethodPatch, "/api/users/7")
+	if rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want %d", rr.Code, http.StatusMethodNotAllowed)
+	}
+	if got := rr.Header().Get("Allow"); got != "GET, HEAD, DELETE" {
+		t.Errorf("got Allow %q", got)
+	}
+	if got := rr.Header().Get(ghttp.HeaderContentType); got != ghttp.MimeProblemJSON {
+		t.Errorf("got content type %q", got)
+	}
+
+	rr = serve(r, http.MethodGet, "/nope")
+	if rr.Code != http.StatusNotFound || rr.Header().Get(ghttp.HeaderContentType) != ghttp.MimeProblemJSON {
+		t.Errorf("got %d %q, want a 404 problem", rr.Code, rr.Header().Get(ghttp.HeaderContentType))
+	}
+
+	r.NotFound(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	r.MethodNotAllowed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+	}))
+
+	if rr := serve(r, http.MethodGet, "/nope"); rr.Code != http.StatusTeapot {
+		t.Errorf("custom not found: got %d", rr.Code)
+	}
+	rr = serve(r, http.MethodPut, "/api/users")
+	if rr.Code != http.StatusConflict || rr.Header().Get("Allow") != "GET, HEAD, POST" {
+		t.Errorf("custom method not allowed: got %d, Allow %q", rr.Code, rr.Header().Get("Allow"))
+	}
+}
+
+func TestRouterURL(t *testing.T) {
+	r := newTestRouter()
+
+	tests := []struct {
+		name   string
+		params []any
+		want   string
+	}{
+		{"home", nil, "/"},
+		{"users.index", []any{"page", 2}, "/api/users?page=2"},
+		{"users.show", []any{"id", 42}, "/api/users/42"},
+		{"users.show", []any{"id", "a b/c"}, "/api/users/a%20b%2Fc"},
+		{"admin.files", []any{"path", "docs/read me.md"}, "/api/admin/files/docs/read%20me.md"},
+	}
+
+	for _, tt := range tests {
+		got, err := r.URL(tt.name, tt.params...)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, params := range [][]any{{}, {"id"}, {1, 2}} {
+		if _, err := r.URL("users.show", params...); err == nil {
+			t.Errorf("users.show %v: expected an error", params)
+		}
+	}
+	if _, err := r.URL("missing"); err == nil {
+		t.Error("unknown route: expected an error")
+	}
+
+	tmpl := template.Must(template.New("").Funcs(r.TemplateFuncs()).Parse(`<a href="{{urlFor "users.show" "id" .}}">`))
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, 42); err != nil {
+		t.Fatal(err)
+	}
+	if got := sb.String(); got != `<a href="/api/users/42">` {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestRouterRoutesAndPattern(t *testing.T) {
+	r := newTestRouter()
+
+	var lines []string
+	for _, rt := range r.Routes() {
+		lines = append(lines, strings.TrimSpace(rt.Method+" "+rt.Path+" "+rt.Name))
+	}
+
+	want := []string{
+		"GET /api/admin",
+		"GET /api/admin/files/{path...} admin.files",
+		"GET /api/users users.index",
+		"POST /api/users",
+		"DELETE /api/users/{id}",
+		"GET /api/users/{id} users.show",
+		"GET /{$} home",
+	}
+	if got := strings.Join(lines, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("got routes\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+
+	if got := r.Pattern(httptest.NewRequest(http.MethodGet, "/api/users/7", nil)); got != "GET /api/users/{id}" {
+		t.Errorf("got pattern %q", got)
+	}
+}
+
+func TestRouterPanics(t *testing.T) {
+	tests := map[string]func(){
+		"use after routes": func() {
+			r := router.New()
+			r.Get("/", write("home"))
+			r.Use(tag("late"))
+		},
+		"use after group routes": func() {
+			r := router.New()
+			r.Group("/api", func(g *router.Router) {
+				g.Get("/users", write("users"))
+			})
+			r.Use(tag("late"))
+		},
+		"duplicate name": func() {
+			r := router.New()
+			r.Get("/a", write("a")).Named("x")
+			r.Get("/b", write("b")).Named("x")
+		},
+	}
+
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
+
+func TestRouterUseInNewGroup(t *testing.T) {
+	r := router.New()
+	r.Get("/", write("home"))
+
+	// A group without routes yet may still add middleware of its own.
+	r.Group("/admin", func(g *router.Router) {
+		g.Use(tag("admin"))
+		g.Get("/stats", write("stats"))
+	})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin/stats", nil))
+	if got := rr.Header().Get("X-Tags"); got != "admin" {
+		t.Errorf("got tags %q", got)
+	}
+}
+
+func TestRouterFallbackRunsRootMiddleware(t *testing.T) {
+	r := router.New()
+	r.Use(middleware.CORS(middleware.CORSOptions{AllowedOrigins: []string{"https://app.example.com"}}))
+	r.Use(tag("root"))
+	r.Post("/items", write("create item"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	req.Header.Set(middleware.HeaderOrigin, "https://app.example.com")
+	req.Header.Set(middleware.HeaderAccessControlRequestMethod, http.MethodPost)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("preflight: got status %d, want %d", rr.Code, http.StatusNoContent)
+	}
+	if got := rr.Header().Get(middleware.HeaderAccessControlAllowOrigin); got != "https://app.example.com" {
+		t.Errorf("preflight: got Allow-Origin %q", got)
+	}
+
+	for _, tt := range []struct {
+		method, target string
+		status         int
+	}{
+		{http.MethodGet, "/items", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/nope", http.StatusNotFound},
+	} {
+		rr := serve(r, tt.method, tt.target)
+		if rr.Code != tt.status {
+			t.Errorf("%s %s: got status %d, want %d", tt.method, tt.target, rr.Code, tt.status)
+		}
+		if got := rr.Header().Get("X-Tags"); got != "root" {
+			t.Errorf("%s %s: got middleware %q, want root", tt.method, tt.target, got)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+// Package server runs HTTP servers with sane limits and graceful shutdown.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/log"
+)
+
+const (
+	defaultAddr              = ":8080"
+	defaultReadHeaderTimeout = 5 * time.Second
+	defaultReadTimeout       = 15 * time.Second
+	defaultWriteTimeout      = 30 * time.Second
+	defaultIdleTimeout       = 60 * time.Second
+	defaultMaxHeaderBytes    = 64 << 10
+	defaultShutdownTimeout   = 30 * time.Second
+)
+
+// Hook releases a resource once the server has stopped.
+// The context expires when the shutdown timeout runs out.
+type Hook func(ctx context.Context) error
+
+// Options configures Run. Zero values select the defaults.
+type Options struct {
+	// Addr defaults to :8080. It is ignored when Listener is set.
+	Addr string
+
+	// Listener serves on an existing listener instead of listening on Addr.
+	Listener net.Listener
+
+	// TLSCertFile and TLSKeyFile serve HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// ReadHeaderTimeout defaults to 5 seconds, ReadTimeout to 15 seconds,
+	// WriteTimeout to 30 seconds and IdleTimeout to 60 seconds.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// MaxHeaderBytes defaults to 64 KB.
+	MaxHeaderBytes int
+
+	// Signals trigger the shutdown. Defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+
+	// BeforeShutdown are called as soon as the shutdown starts, while
+	// requests are still served, such as to fail readiness checks.
+	BeforeShutdown []func()
+
+	// ShutdownDelay keeps serving this long after BeforeShutdown, so load
+	// balancers can stop sending traffic before the listener closes.
+	ShutdownDelay time.Duration
+
+	// ShutdownTimeout bounds draining the connections and running the
+	// OnShutdown hooks. Defaults to 30 seconds.
+	ShutdownTimeout time.Duration
+
+	// OnShutdown hooks run in order once the connections are drained.
+	OnShutdown []Hook
+
+	// Logger defaults to log.CreateLogger.
+	Logger *slog.Logger
+}
+
+func (opts *Options) setDefaults() {
+	if opts.Addr == "" {
+		opts.Addr = defaultAddr
+	}
+	if opts.ReadHeaderTimeout == 0 {
+		opts.ReadHeaderTimeout = defaultReadHeaderTimeout
+	}
+	if opts.ReadTimeout == 0 {
+		opts.ReadTimeout = defaultReadTimeout
+	}
+	if opts.WriteTimeout == 0 {
+		opts.WriteTimeout = defaultWriteTimeout
+	}
+	if opts.IdleTimeout == 0 {
+		opts.IdleTimeout = defaultIdleTimeout
+	}
+	if opts.MaxHeaderBytes == 0 {
+		opts.MaxHeaderBytes = defaultMaxHeaderBytes
+	}
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if opts.ShutdownTimeout == 0 {
+		opts.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.CreateLogger()
+	}
+}
+
+// Run serves handler until ctx is canceled or a shutdown signal arrives,
+// then stops accepting connections, waits for in-flight requests and runs the
+// shutdown hooks. It returns nil after a clean shutdown, and otherwise the
+// errors of serving, draining and the hooks.
+//
+// Request contexts are canceled once draining starts, so long-lived handlers
+// such as event streams return instead of holding up the shutdown.
+func Run(ctx context.Context, handler http.Handler, opts Options) error {
+	opts.setDefaults()
+	logger := opts.Logger
+
+	ctx, stop := signal.NotifyContext(ctx, opts.Signals...)
+	defer stop()
+
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
+	srv := &http.Server{
+		Handler:           handler,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+	srv.RegisterOnShutdown(cancelRequests)
+
+	ln := opts.Listener
+	if ln == nil {
+		var err error
+		if ln, err = net.Listen("tcp", opts.Addr); err != nil {
+			return fmt.Errorf("listen on %s: %w", opts.Addr, err)
+		}
+	}
+
+	tls := opts.TLSCertFile != "" && opts.TLSKeyFile != ""
+	serveErr := make(chan error, 1)
+	go func() {
+		if tls {
+			serveErr <- srv.ServeTLS(ln, opts.TLSCertFile, opts.TLSKeyFile)
+		} else {
+			serveErr <- srv.Serve(ln)
+		}
+	}()
+	logger.Info("server started", "addr", ln.Addr().String(), "tls", tls)
+
+	var errs []error
+	serving := true
+
+	select {
+	case err := <-serveErr:
+		// The server stopped on its own; the hooks still release resources.
+		errs = append(errs, fmt.Errorf("serve: %w", err))
+		serving = false
+	case <-ctx.Done():
+		// A second signal kills the process the default way.
+		stop()
+		logger.Info("shutting down", "timeout", opts.ShutdownTimeout)
+
+		for _, fn := range opts.BeforeShutdown {
+			fn()
+		}
+		if opts.ShutdownDelay > 0 {
+			time.Sleep(opts.ShutdownDelay)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("drain connections: %w", err))
+		_ = srv.Close()
+	}
+	if serving {
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, fmt.Errorf("serve: %w", err))
+		}
+	}
+
+	for i, hook := range opts.OnShutdown {
+		if err := hook(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		logger.Error("server stopped with errors", "reason", err)
+		return err
+	}
+
+	logger.Info("server stopped")
+	return nil
+}
@@ -0,0 +1,179 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/server"
+	"github.com/ferdiebergado/gopherkit/http/sse"
+)
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ln
+}
+
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestRunDrainsAndRunsHooks(t *testing.T) {
+	ln := listen(t)
+	started := make(chan struct{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- server.Run(ctx, handler, server.Options{
+			Listener:       ln,
+			Logger:         quietLogger(),
+			BeforeShutdown: []func(){func() { record("before") }},
+			OnShutdown: []server.Hook{
+				func(context.Context) error { record("hook 1"); return nil },
+				func(context.Context) error { record("hook 2"); return nil },
+			},
+		})
+	}()
+
+	body := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		b, _ := io.ReadAll(res.Body)
+		body <- string(b)
+	}()
+
+	<-started
+	cancel()
+
+	if got := <-body; got != "done" {
+		t.Errorf("in-flight request got %q, want it drained", got)
+	}
+	if err := <-runErr; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if got := strings.Join(order, ","); got != "before,hook 1,hook 2" {
+		t.Errorf("got order %q", got)
+	}
+	if _, err := http.Get("http://" + ln.Addr().String()); err == nil {
+		t.Error("server still accepts connections")
+	}
+}
+
+func TestRunEndsStreams(t *testing.T) {
+	ln := listen(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = sse.Serve(w, r, make(chan sse.Event), sse.Options{})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- server.Run(ctx, handler, server.Options{
+			Listener:        ln,
+			Logger:          quietLogger(),
+			ShutdownTimeout: 2 * time.Second,
+		})
+	}()
+
+	res, err := http.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer res.Body.Close()
+
+	start := time.Now()
+	cancel()
+
+	if err := <-runErr; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %s, the open stream held it up", elapsed)
+	}
+}
+
+func TestRunReturnsErrors(t *testing.T) {
+	hookErr := errors.New("close database")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := server.Run(ctx, http.NotFoundHandler(), server.Options{
+		Listener: listen(t),
+		Logger:   quietLogger(),
+		OnShutdown: []server.Hook{
+			func(context.Context) error { return hookErr },
+		},
+	})
+	if !errors.Is(err, hookErr) {
+		t.Errorf("got error %v, want the hook error", err)
+	}
+}
+
+func TestRunListenError(t *testing.T) {
+	ln := listen(t)
+	defer ln.Close()
+
+	err := server.Run(context.Background(), http.NotFoundHandler(), server.Options{
+		Addr:   ln.Addr().String(),
+		Logger: quietLogger(),
+	})
+	if err == nil || !strings.Contains(err.Error(), "listen") {
+		t.Errorf("got error %v, want a listen error", err)
+	}
+}
+
+func TestRunServeError(t *testing.T) {
+	var hookRan bool
+
+	err := server.Run(context.Background(), http.NotFoundHandler(), server.Options{
+		Listener:    listen(t),
+		Logger:      quietLogger(),
+		TLSCertFile: "missing.crt",
+		TLSKeyFile:  "missing.key",
+		OnShutdown: []server.Hook{
+			func(context.Context) error { hookRan = true; return nil },
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "serve") {
+		t.Errorf("got error %v, want a serve error", err)
+	}
+	if !hookRan {
+		t.Error("hooks should run when serving fails")
+	}
+}
@@ -0,0 +1,345 @@
+package session
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+const (
+	defaultCookieName  = "session"
+	defaultIdleTimeout = 30 * time.Minute
+	defaultLifetime    = 24 * time.Hour
+
+	// minKeySize is the smallest key NewCookieManager accepts.
+	minKeySize = 32
+
+	// Sessions that were only read are saved again at most this often,
+	// to extend their idle timeout without writing on every request.
+	touchInterval = time.Minute
+
+	maxCookieSize = 4096
+)
+
+// ErrCookieTooLarge is returned when an encrypted session does not fit in a cookie.
+var ErrCookieTooLarge = errors.New("session cookie too large")
+
+// Manager loads and saves the sessions of requests.
+type Manager struct {
+	store Store
+	aead  cipher.AEAD
+
+	// CookieName defaults to session.
+	CookieName string
+
+	// Domain and Path scope the cookie. Path defaults to /.
+	Domain string
+	Path   string
+
+	// Secure restricts the cookie to HTTPS.
+	Secure bool
+
+	// SameSite defaults to Lax.
+	SameSite http.SameSite
+
+	// Persist keeps the cookie after the browser is closed, until Lifetime ends.
+	Persist bool
+
+	// IdleTimeout ends sessions that were not used for this long.
+	// Defaults to 30 minutes.
+	IdleTimeout time.Duration
+
+	// Lifetime ends sessions this long after they were created, however
+	// active they are. Defaults to 24 hours.
+	Lifetime time.Duration
+}
+
+// Creates a Manager that keeps sessions in store and only their id in the cookie
+func NewManager(store Store) *Manager {
+	m := newManager()
+	m.store = store
+	return m
+}
+
+// Creates a Manager that keeps sessions in a cookie, encrypted and
+// authenticated with AES-GCM under a key derived from key.
+// Cookies are limited to 4 KB, so only small sessions fit.
+// It panics when key is shorter than 32 bytes.
+func NewCookieManager(key []byte) *Manager {
+	if len(key) < minKeySize {
+		panic(fmt.Sprintf("session: key must be at least %d bytes, got %d", minKeySize, len(key)))
+	}
+
+	derived := sha256.Sum256(append([]byte("gopherkit session "), key...))
+
+	block, err := aes.NewCipher(derived[:])
+	if err != nil {
+		panic(fmt.Sprintf("session: new cipher: %v", err))
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(fmt.Sprintf("session: new gcm: %v", err))
+	}
+
+	m := newManager()
+	m.aead = aead
+	return m
+}
+
+func newManager() *Manager {
+	return &Manager{
+		CookieName:  defaultCookieName,
+		Path:        "/",
+		Secure:      true,
+		SameSite:    http.SameSiteLaxMode,
+		IdleTimeout: defaultIdleTimeout,
+		Lifetime:    defaultLifetime,
+	}
+}
+
+// Middleware loads the session of the request, available through FromRequest,
+// and saves it before the response is written.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add(ghttp.HeaderVary, "Cookie")
+
+		s := m.load(r)
+		r = r.WithContext(withSession(r.Context(), s))
+
+		sw := &sessionWriter{ResponseWriter: w, commit: func() { m.commit(w, r, s) }}
+		next.ServeHTTP(sw, r)
+		sw.commitOnce()
+	})
+}
+
+// TemplateFuncs exposes the session to templates as session, for use as
+// RendererConfig.RequestFuncs or with response.HTMLFuncs:
+//
+//	{{with (session).GetString "name"}}Hello {{.}}{{end}}
+func (m *Manager) TemplateFuncs(_ http.ResponseWriter, r *http.Request) template.FuncMap {
+	s, _ := r.Context().Value(sessionKey).(*Session)
+	if s == nil {
+		// Templates are parsed without a session.
+		s = newSession(time.Now())
+	}
+
+	return template.FuncMap{
+		"session": func() *Session {
+			return s
+		},
+	}
+}
+
+// load returns the session named by the cookie, or a new one when it is
+// missing, invalid or expired.
+func (m *Manager) load(r *http.Request) *Session {
+	now := time.Now()
+
+	c, err := r.Cookie(m.CookieName)
+	if err != nil {
+		return newSession(now)
+	}
+
+	rec, err := m.decode(r, c.Value)
+	if err != nil {
+		slog.Warn("invalid session", "reason", err, "method", r.Method, "path", r.URL.Path)
+		return newSession(now)
+	}
+	if rec == nil {
+		return newSession(now)
+	}
+
+	if m.expired(rec, now) {
+		if m.store != nil {
+			if err := m.store.Delete(r.Context(), rec.ID); err != nil {
+				slog.Error("delete expired session", "reason", err)
+			}
+		}
+		return newSession(now)
+	}
+
+	if rec.Values == nil {
+		rec.Values = make(map[string]any)
+	}
+	return &Session{rec: *rec, loadedID: rec.ID}
+}
+
+// decode reads a session from a cookie value. It returns nil when the store
+// does not know the session.
+func (m *Manager) decode(r *http.Request, value string) (*record, error) {
+	var data []byte
+
+	if m.store != nil {
+		found, ok, err := m.store.Find(r.Context(), value)
+		if err != nil {
+			return nil, fmt.Errorf("find session: %w", err)
+		}
+		if !ok {
+			return nil, nil
+		}
+		data = found
+	} else {
+		opened, err := m.open(value)
+		if err != nil {
+			return nil, err
+		}
+		data = opened
+	}
+
+	var rec record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+	return &rec, nil
+}
+
+func (m *Manager) expired(rec *record, now time.Time) bool {
+	return now.Sub(rec.LastSeen) >= m.IdleTimeout || now.Sub(rec.Created) >= m.Lifetime
+}
+
+// commit saves the session if needed and sets or clears the cookie.
+func (m *Manager) commit(w http.ResponseWriter, r *http.Request, s *Session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	ctx := r.Context()
+
+	if m.store != nil && s.replaced && s.loadedID != "" {
+		if err := m.store.Delete(ctx, s.loadedID); err != nil {
+			slog.Error("delete replaced session", "reason", err)
+		}
+	}
+
+	touch := s.loadedID != "" && now.Sub(s.rec.LastSeen) >= touchInterval
+	if !s.modified && !touch {
+		if s.destroyed && s.loadedID != "" {
+			http.SetCookie(w, m.cookie("", time.Time{}, -1))
+		}
+		return
+	}
+
+	s.rec.LastSeen = now
+	expiry := now.Add(m.IdleTimeout)
+	if end := s.rec.Created.Add(m.Lifetime); end.Before(expiry) {
+		expiry = end
+	}
+
+	value, err := m.encode(r, &s.rec, expiry)
+	if err != nil {
+		slog.Error("save session", "reason", err, "method", r.Method, "path", r.URL.Path)
+		return
+	}
+
+	http.SetCookie(w, m.cookie(value, s.rec.Created.Add(m.Lifetime), 0))
+}
+
+// encode stores a session and returns the cookie value naming it.
+func (m *Manager) encode(r *http.Request, rec *record, expiry time.Time) (string, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return "", fmt.Errorf("encode session: %w", err)
+	}
+
+	if m.store != nil {
+		if err := m.store.Save(r.Context(), rec.ID, data, expiry); err != nil {
+			return "", fmt.Errorf("store session: %w", err)
+		}
+		return rec.ID, nil
+	}
+
+	value := m.seal(data)
+	if len(m.CookieName)+len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return value, nil
+}
+
+// seal encrypts data, prefixing the ciphertext with its nonce.
+func (m *Manager) seal(data []byte) string {
+	nonce := make([]byte, m.aead.NonceSize(), m.aead.NonceSize()+len(data)+m.aead.Overhead())
+	_, _ = rand.Read(nonce)
+	return base64.RawURLEncoding.EncodeToString(m.aead.Seal(nonce, nonce, data, []byte(m.CookieName)))
+}
+
+func (m *Manager) open(value string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) < m.aead.NonceSize() {
+		return nil, errors.New("malformed session cookie")
+	}
+
+	nonce, ciphertext := b[:m.aead.NonceSize()], b[m.aead.NonceSize():]
+	data, err := m.aead.Open(nil, nonce, ciphertext, []byte(m.CookieName))
+	if err != nil {
+		return nil, errors.New("session cookie failed authentication")
+	}
+	return data, nil
+}
+
+func (m *Manager) cookie(value string, expires time.Time, maxAge int) *http.Cookie {
+	c := &http.Cookie{
+		Name:     m.CookieName,
+		Value:    value,
+		Domain:   m.Domain,
+		Path:     m.Path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   m.Secure,
+		SameSite: m.SameSite,
+	}
+	if m.Persist && maxAge == 0 {
+		c.Expires = expires
+	}
+	return c
+}
+
+// sessionWriter commits the session before the response headers are sent.
+type sessionWriter struct {
+	http.ResponseWriter
+	commit    func()
+	committed bool
+}
+
+func (sw *sessionWriter) commitOnce() {
+	if !sw.committed {
+		sw.committed = true
+		sw.commit()
+	}
+}
+
+func (sw *sessionWriter) WriteHeader(status int) {
+	sw.commitOnce()
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *sessionWriter) Write(b []byte) (int, error) {
+	sw.commitOnce()
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *sessionWriter) Flush() {
+	sw.commitOnce()
+	_ = http.NewResponseController(sw.ResponseWriter).Flush()
+}
+
+func (sw *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	sw.commitOnce()
+	return http.NewResponseController(sw.ResponseWriter).Hijack()
+}
+
+func (sw *sessionWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
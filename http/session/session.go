@@ -0,0 +1,199 @@
+// Package session keeps per-client state across requests, either in an
+// encrypted cookie or in a server-side Store.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type contextKey string
+
+const sessionKey contextKey = "session"
+
+const idSize = 32
+
+// Session holds the values of one client.
+// Values are stored as JSON, so numbers read back from a stored session are
+// float64 and structs become maps; the typed getters smooth this over for
+// the common cases.
+type Session struct {
+	mu sync.Mutex
+
+	rec record
+
+	// loadedID is the id the session was loaded with, "" for a new session.
+	loadedID string
+
+	modified  bool
+	replaced  bool
+	destroyed bool
+}
+
+// record is the stored form of a session.
+type record struct {
+	ID       string         `json:"id"`
+	Created  time.Time      `json:"created"`
+	LastSeen time.Time      `json:"last_seen"`
+	Values   map[string]any `json:"values"`
+}
+
+func newSession(now time.Time) *Session {
+	return &Session{rec: record{
+		ID:       newID(),
+		Created:  now,
+		LastSeen: now,
+		Values:   make(map[string]any),
+	}}
+}
+
+// FromRequest returns the session of the request.
+// It panics if the request did not pass through Manager.Middleware.
+func FromRequest(r *http.Request) *Session {
+	s, ok := r.Context().Value(sessionKey).(*Session)
+	if !ok {
+		panic("session: request has no session; is Manager.Middleware installed?")
+	}
+	return s
+}
+
+func withSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey, s)
+}
+
+// ID returns the id of the session. It changes on Renew and Destroy.
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rec.ID
+}
+
+// IsNew reports whether the session was created by this request.
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadedID == ""
+}
+
+// Get returns the value stored under key, or nil.
+func (s *Session) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rec.Values[key]
+}
+
+// GetString returns the string stored under key, or "".
+func (s *Session) GetString(key string) string {
+	v, _ := s.Get(key).(string)
+	return v
+}
+
+// GetBool returns the bool stored under key, or false.
+func (s *Session) GetBool(key string) bool {
+	v, _ := s.Get(key).(bool)
+	return v
+}
+
+// GetInt returns the integer stored under key, or 0.
+func (s *Session) GetInt(key string) int {
+	switch v := s.Get(key).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// Has reports whether a value is stored under key.
+func (s *Session) Has(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.rec.Values[key]
+	return ok
+}
+
+// Keys returns the sorted keys of the stored values.
+func (s *Session) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make([]string, 0, len(s.rec.Values))
+	for k := range s.rec.Values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Put stores a value under key. It must be encodable as JSON.
+func (s *Session) Put(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec.Values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rec.Values[key]; ok {
+		delete(s.rec.Values, key)
+		s.modified = true
+	}
+}
+
+// Pop returns the value stored under key and removes it.
+func (s *Session) Pop(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.rec.Values[key]
+	if ok {
+		delete(s.rec.Values, key)
+		s.modified = true
+	}
+	return v
+}
+
+// PopString returns the string stored under key and removes it.
+func (s *Session) PopString(key string) string {
+	v, _ := s.Pop(key).(string)
+	return v
+}
+
+// Renew gives the session a new id while keeping its values.
+// Call it when the privileges of the client change, such as on login and
+// logout, so an id planted before login cannot be used afterwards.
+func (s *Session) Renew() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec.ID = newID()
+	s.replaced = true
+	s.modified = true
+}
+
+// Destroy removes every value and the stored session.
+// Values put after Destroy start a session with a new id.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rec = newSession(time.Now()).rec
+	s.replaced = true
+	s.destroyed = true
+	s.modified = false
+}
+
+func newID() string {
+	b := make([]byte, idSize)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,245 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/response"
+	"github.com/ferdiebergado/gopherkit/http/session"
+)
+
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+// client replays the cookies of previous responses like a browser.
+type client struct {
+	t       *testing.T
+	h       http.Handler
+	cookies map[string]*http.Cookie
+}
+
+func newClient(t *testing.T, m *session.Manager, fn http.HandlerFunc) *client {
+	return &client{t: t, h: m.Middleware(fn), cookies: make(map[string]*http.Cookie)}
+}
+
+func (c *client) do(path string) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, ck := range c.cookies {
+		req.AddCookie(ck)
+	}
+
+	rr := httptest.NewRecorder()
+	c.h.ServeHTTP(rr, req)
+
+	for _, ck := range rr.Result().Cookies() {
+		if ck.MaxAge < 0 {
+			delete(c.cookies, ck.Name)
+		} else {
+			c.cookies[ck.Name] = ck
+		}
+	}
+	return rr
+}
+
+// counter counts the visits of a client and supports login and logout.
+func counter(w http.ResponseWriter, r *http.Request) {
+	s := session.FromRequest(r)
+
+	switch r.URL.Path {
+	case "/login":
+		s.Renew()
+		s.Put("user", "gopher")
+	case "/logout":
+		s.Destroy()
+		return
+	case "/peek":
+	default:
+		s.Put("visits", s.GetInt("visits")+1)
+	}
+
+	_, _ = w.Write([]byte(s.ID()))
+}
+
+func managers() map[string]*session.Manager {
+	return map[string]*session.Manager{
+		"cookie": session.NewCookieManager(key),
+		"store":  session.NewManager(session.NewMemoryStore()),
+	}
+}
+
+func TestManager(t *testing.T) {
+	for name, m := range managers() {
+		t.Run(name, func(t *testing.T) {
+			c := newClient(t, m, counter)
+
+			if rr := c.do("/peek"); len(rr.Result().Cookies()) != 0 {
+				t.Error("an unmodified new session should not set a cookie")
+			}
+
+			c.do("/")
+			c.do("/")
+			id := c.do("/").Body.String()
+
+			var visits int
+			c.h = m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				visits = session.FromRequest(r).GetInt("visits")
+			}))
+			c.do("/")
+			if visits != 3 {
+				t.Errorf("got %d visits, want 3", visits)
+			}
+
+			c.h = m.Middleware(http.HandlerFunc(counter))
+			loginID := c.do("/login").Body.String()
+			if loginID == id {
+				t.Error("login should renew the session id")
+			}
+			if got := c.do("/peek").Body.String(); got != loginID {
+				t.Errorf("got id %q after login, want %q", got, loginID)
+			}
+
+			rr := c.do("/logout")
+			cookies := rr.Result().Cookies()
+			if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+				t.Errorf("logout should expire the cookie, got %v", cookies)
+			}
+			if got := c.do("/peek").Body.String(); got == loginID {
+				t.Error("the session should be gone after logout")
+			}
+		})
+	}
+}
+
+func TestManagerReplacedSessionIsDeleted(t *testing.T) {
+	store := session.NewMemoryStore()
+	m := session.NewManager(store)
+	c := newClient(t, m, counter)
+
+	c.do("/")
+	old := c.cookies["session"]
+	c.do("/login")
+
+	if store.Len() != 1 {
+		t.Errorf("got %d stored sessions, want 1", store.Len())
+	}
+
+	// Replaying the id from before login yields a fresh session.
+	attacker := newClient(t, m, counter)
+	attacker.cookies["session"] = old
+	if got := attacker.do("/peek").Body.String(); got == old.Value {
+		t.Error("the pre-login id should no longer be valid")
+	}
+}
+
+func TestManagerTimeouts(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(*session.Manager)
+	}{
+		{"idle", func(m *session.Manager) { m.IdleTimeout = 20 * time.Millisecond }},
+		{"lifetime", func(m *session.Manager) { m.Lifetime = 20 * time.Millisecond }},
+	}
+
+	for _, tt := range tests {
+		for name, m := range managers() {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				tt.set(m)
+				c := newClient(t, m, counter)
+
+				id := c.do("/").Body.String()
+				if got := c.do("/peek").Body.String(); got != id {
+					t.Fatalf("session should still be valid")
+				}
+
+				time.Sleep(30 * time.Millisecond)
+				if got := c.do("/peek").Body.String(); got == id {
+					t.Error("session should have expired")
+				}
+			})
+		}
+	}
+}
+
+func TestCookieManagerRejectsTampering(t *testing.T) {
+	m := session.NewCookieManager(key)
+	c := newClient(t, m, counter)
+	id := c.do("/").Body.String()
+
+	ck := c.cookies["session"]
+	if strings.Contains(ck.Value, "visits") {
+		t.Error("cookie should be encrypted")
+	}
+
+	tampered := *ck
+	b := []byte(tampered.Value)
+	b[len(b)/2] ^= 1
+	tampered.Value = string(b)
+	c.cookies["session"] = &tampered
+
+	if got := c.do("/peek").Body.String(); got == id {
+		t.Error("a tampered cookie should start a new session")
+	}
+
+	other := session.NewCookieManager([]byte("another key of thirty-two bytes!"))
+	c2 := newClient(t, other, counter)
+	c2.cookies["session"] = ck
+	if got := c2.do("/peek").Body.String(); got == id {
+		t.Error("a cookie sealed with another key should be rejected")
+	}
+}
+
+func TestCookieManagerRejectsShortKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewCookieManager with a short key did not panic")
+		}
+	}()
+
+	session.NewCookieManager([]byte("too short"))
+}
+
+func TestCookieManagerTooLarge(t *testing.T) {
+	m := session.NewCookieManager(key)
+	c := newClient(t, m, func(w http.ResponseWriter, r *http.Request) {
+		session.FromRequest(r).Put("blob", strings.Repeat("x", 5000))
+	})
+
+	if rr := c.do("/"); len(rr.Result().Cookies()) != 0 {
+		t.Error("an oversized session should not be written")
+	}
+}
+
+func TestTemplateFuncs(t *testing.T) {
+	m := session.NewCookieManager(key)
+
+	rd, err := response.NewRenderer(response.RendererConfig{
+		FS: fstest.MapFS{
+			"layout.html":     {Data: []byte(`{{template "content" .}}`)},
+			"pages/home.html": {Data: []byte(`{{define "content"}}{{with (session).GetString "user"}}Hello {{.}}{{else}}Hello stranger{{end}}{{end}}`)},
+		},
+		RequestFuncs: []response.RequestFuncs{m.TemplateFuncs},
+	})
+	if err != nil {
+		t.Fatalf("new renderer: %v", err)
+	}
+
+	c := newClient(t, m, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			session.FromRequest(r).Put("user", "gopher")
+		}
+		rd.Render(w, r, http.StatusOK, "home", nil)
+	})
+
+	if got := c.do("/").Body.String(); got != "Hello stranger" {
+		t.Errorf("got %q", got)
+	}
+	c.do("/login")
+	if got := c.do("/").Body.String(); got != "Hello gopher" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,73 @@
+package session
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/internal/expiring"
+)
+
+const defaultSweepInterval = time.Minute
+
+// Store keeps encoded sessions on the server, addressed by their id.
+type Store interface {
+	// Find returns the data of a session that has not expired.
+	// It reports false when there is no such session.
+	Find(ctx context.Context, id string) ([]byte, bool, error)
+
+	// Save stores the data of a session until expiry.
+	Save(ctx context.Context, id string, data []byte, expiry time.Time) error
+
+	// Delete removes a session. Deleting an unknown session is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps sessions in memory. They are lost on restart and are not
+// shared between instances. Expired sessions are evicted periodically.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions *expiring.Map[[]byte]
+
+	// SweepInterval is how often expired sessions are evicted. Defaults to a minute.
+	SweepInterval time.Duration
+}
+
+// Creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions:      expiring.New[[]byte](),
+		SweepInterval: defaultSweepInterval,
+	}
+}
+
+func (s *MemoryStore) Find(_ context.Context, id string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, ok := s.sessions.Get(id, time.Now())
+	return data, ok, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, id string, data []byte, expiry time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions.Sweep(time.Now(), s.SweepInterval)
+	s.sessions.Set(id, append([]byte(nil), data...), expiry)
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions.Delete(id)
+	return nil
+}
+
+// Len returns the number of stored sessions, including expired ones not yet evicted.
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions.Len()
+}
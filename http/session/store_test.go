@@ -0,0 +1,50 @@
+package session_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/http/session"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := session.NewMemoryStore()
+	store.SweepInterval = 0
+
+	if err := store.Save(ctx, "a", []byte("alpha"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(ctx, "b", []byte("beta"), time.Now().Add(10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok, err := store.Find(ctx, "a")
+	if err != nil || !ok || string(data) != "alpha" {
+		t.Errorf("find a: got %q, %v, %v", data, ok, err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, _ := store.Find(ctx, "b"); ok {
+		t.Error("expired session b was found")
+	}
+
+	// Saving sweeps the expired sessions.
+	if err := store.Save(ctx, "c", []byte("gamma"), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if store.Len() != 2 {
+		t.Errorf("got %d sessions, want 2", store.Len())
+	}
+
+	if err := store.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Find(ctx, "a"); ok {
+		t.Error("deleted session a was found")
+	}
+	if err := store.Delete(ctx, "unknown"); err != nil {
+		t.Errorf("delete unknown: %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+// Reader parses server-sent events from a stream.
+// It is meant for asserting on events in tests.
+type Reader struct {
+	scanner *bufio.Scanner
+}
+
+// Creates a Reader over an event stream
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanLines)
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next event, including events that only set retry.
+// It returns io.EOF when the stream ends.
+func (rd *Reader) Next() (Event, error) {
+	var (
+		e       Event
+		data    []string
+		hasData bool
+		seen    bool
+	)
+
+	for rd.scanner.Scan() {
+		line := rd.scanner.Text()
+
+		if line == "" {
+			if hasData || seen {
+				e.Data = strings.Join(data, "\n")
+				return e, nil
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			e.ID = value
+			seen = true
+		case "event":
+			e.Event = value
+			seen = true
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				e.Retry = time.Duration(ms) * time.Millisecond
+				seen = true
+			}
+		}
+	}
+
+	if err := rd.scanner.Err(); err != nil {
+		return Event{}, fmt.Errorf("sse: read: %w", err)
+	}
+	return Event{}, io.EOF
+}
+
+// Stream is a client connection to an event stream.
+type Stream struct {
+	*Reader
+	Response *http.Response
+}
+
+// Close closes the connection.
+func (s *Stream) Close() error {
+	return s.Response.Body.Close()
+}
+
+// Connect opens an event stream at url, resuming after lastEventID if set.
+func Connect(ctx context.Context, client *http.Client, url, lastEventID string) (*Stream, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sse: new request: %w", err)
+	}
+	req.Header.Set("Accept", ghttp.MimeEventStream)
+	if lastEventID != "" {
+		req.Header.Set(HeaderLastEventID, lastEventID)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sse: connect: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("sse: unexpected status %s", res.Status)
+	}
+
+	return &Stream{Reader: NewReader(res.Body), Response: res}, nil
+}
+
+// scanLines splits on \n, \r\n or a lone \r.
+func scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	for i, b := range data {
+		switch b {
+		case '\n':
+			return i + 1, data[:i], nil
+		case '\r':
+			if i+1 < len(data) {
+				if data[i+1] == '\n' {
+					return i + 2, data[:i], nil
+				}
+				return i + 1, data[:i], nil
+			}
+			if atEOF {
+				return i + 1, data[:i], nil
+			}
+			// Need more data to know whether \n follows.
+			return 0, nil, nil
+		}
+	}
+
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
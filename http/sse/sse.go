@@ -0,0 +1,206 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/response"
+)
+
+const (
+	HeaderLastEventID = "Last-Event-ID"
+
+	defaultHeartbeat = 15 * time.Second
+)
+
+// ErrInvalidField is returned when an event id or name contains a line break.
+var ErrInvalidField = errors.New("sse: field must not contain line breaks")
+
+// Event is a single server-sent event.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// Writer writes server-sent events to a response.
+// It is safe for concurrent use.
+type Writer struct {
+	// WriteTimeout bounds every write, so a client that stops reading is
+	// dropped. Defaults to response.DefaultStreamWriteTimeout.
+	WriteTimeout time.Duration
+
+	mu sync.Mutex
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewWriter sets the event stream headers and commits the response.
+// The server's deadlines are managed with response.ExtendDeadlines, so the
+// stream is not cut off by the timeouts meant for regular requests.
+func NewWriter(w http.ResponseWriter) (*Writer, error) {
+	h := w.Header()
+	h.Set(ghttp.HeaderContentType, ghttp.MimeEventStream)
+	h.Set("Cache-Control", "no-cache")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sw := &Writer{
+		WriteTimeout: response.DefaultStreamWriteTimeout,
+		w:            w,
+		rc:           http.NewResponseController(w),
+	}
+	if err := response.ExtendDeadlines(sw.rc, sw.WriteTimeout); err != nil {
+		return nil, fmt.Errorf("sse: %w", err)
+	}
+	if err := sw.rc.Flush(); err != nil {
+		return nil, fmt.Errorf("sse: flush headers: %w", err)
+	}
+
+	return sw, nil
+}
+
+// Send writes an event and flushes it to the client.
+func (sw *Writer) Send(e Event) error {
+	if strings.ContainsAny(e.ID, "\r\n") || strings.ContainsAny(e.Event, "\r\n") {
+		return ErrInvalidField
+	}
+
+	var b strings.Builder
+	if e.ID != "" {
+		b.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Event != "" {
+		b.WriteString("event: " + e.Event + "\n")
+	}
+	if e.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+	if e.Data != "" || e.ID != "" || e.Event != "" {
+		for _, line := range splitLines(e.Data) {
+			b.WriteString("data: " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	return sw.write(b.String())
+}
+
+// Comment writes a comment line, which clients ignore.
+// Comments keep idle connections from being closed by proxies.
+func (sw *Writer) Comment(text string) error {
+	var b strings.Builder
+	for _, line := range splitLines(text) {
+		b.WriteString(": " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	return sw.write(b.String())
+}
+
+func (sw *Writer) write(s string) error {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if err := response.ExtendDeadlines(sw.rc, sw.WriteTimeout); err != nil {
+		return fmt.Errorf("sse: %w", err)
+	}
+	if _, err := sw.w.Write([]byte(s)); err != nil {
+		return fmt.Errorf("sse: write: %w", err)
+	}
+	if err := sw.rc.Flush(); err != nil {
+		return fmt.Errorf("sse: flush: %w", err)
+	}
+	return nil
+}
+
+// LastEventID returns the id of the last event the reconnecting client received.
+func LastEventID(r *http.Request) string {
+	return r.Header.Get(HeaderLastEventID)
+}
+
+// Options configures Serve.
+type Options struct {
+	// Heartbeat is the interval between keep-alive comments. Defaults to 15 seconds.
+	Heartbeat time.Duration
+
+	// Retry tells the client how long to wait before reconnecting.
+	Retry time.Duration
+
+	// WriteTimeout sets Writer.WriteTimeout.
+	WriteTimeout time.Duration
+}
+
+// Serve streams the events received from ch until ch is closed or the
+// request context is canceled, sending heartbeats while idle.
+// A canceled request is not an error.
+func Serve(w http.ResponseWriter, r *http.Request, events <-chan Event, opts Options) error {
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = defaultHeartbeat
+	}
+
+	sw, err := NewWriter(w)
+	if err != nil {
+		return err
+	}
+	if opts.WriteTimeout > 0 {
+		sw.WriteTimeout = opts.WriteTimeout
+	}
+
+	if opts.Retry > 0 {
+		if err := sw.Send(Event{Retry: opts.Retry}); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(opts.Heartbeat)
+	defer ticker.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Debug("sse client disconnected", "path", r.URL.Path, "last_event_id", LastEventID(r))
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := sw.Send(e); err != nil {
+				return ignoreCanceled(ctx, err)
+			}
+			ticker.Reset(opts.Heartbeat)
+		case <-ticker.C:
+			if err := sw.Comment("heartbeat"); err != nil {
+				return ignoreCanceled(ctx, err)
+			}
+		}
+	}
+}
+
+// ignoreCanceled drops write errors caused by the client going away.
+func ignoreCanceled(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// splitLines splits s on any of the line endings allowed by the event stream format.
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,211 @@
+package sse_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+	"github.com/ferdiebergado/gopherkit/http/server"
+	"github.com/ferdiebergado/gopherkit/http/sse"
+)
+
+func TestWriterSend(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	sw, err := sse.NewWriter(rr)
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+
+	if err := sw.Send(sse.Event{ID: "7", Event: "update", Data: "line 1\nline 2\r\nline 3", Retry: 3 * time.Second}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if err := sw.Comment("ping"); err != nil {
+		t.Fatalf("comment: %v", err)
+	}
+
+	if ct := rr.Header().Get(ghttp.HeaderContentType); ct != ghttp.MimeEventStream {
+		t.Errorf("got content type %q, want %q", ct, ghttp.MimeEventStream)
+	}
+	if cc := rr.Header().Get("Cache-Control"); cc != "no-cache" {
+		t.Errorf("got Cache-Control %q, want no-cache", cc)
+	}
+
+	want := "id: 7\nevent: update\nretry: 3000\ndata: line 1\ndata: line 2\ndata: line 3\n\n: ping\n\n"
+	if rr.Body.String() != want {
+		t.Errorf("got %q, want %q", rr.Body.String(), want)
+	}
+
+	e, err := sse.NewReader(strings.NewReader(rr.Body.String())).Next()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if e.ID != "7" || e.Event != "update" || e.Data != "line 1\nline 2\nline 3" || e.Retry != 3*time.Second {
+		t.Errorf("got event %+v", e)
+	}
+}
+
+func TestWriterSendInvalidField(t *testing.T) {
+	sw, err := sse.NewWriter(httptest.NewRecorder())
+	if err != nil {
+		t.Fatalf("new writer: %v", err)
+	}
+
+	if err := sw.Send(sse.Event{Event: "bad\nname"}); !errors.Is(err, sse.ErrInvalidField) {
+		t.Errorf("got error %v, want ErrInvalidField", err)
+	}
+}
+
+func TestServe(t *testing.T) {
+	done := make(chan error, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := make(chan sse.Event)
+		go func() {
+			start := 0
+			if id := sse.LastEventID(r); id == "1" {
+				start = 2
+			}
+			for i := start; i < 3; i++ {
+				select {
+				case events <- sse.Event{ID: string(rune('0' + i)), Data: "tick"}:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}()
+
+		done <- sse.Serve(w, r, events, sse.Options{Heartbeat: 10 * time.Millisecond})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := sse.Connect(ctx, srv.Client(), srv.URL, "1")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	e, err := stream.Next()
+	if err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if e.ID != "2" || e.Data != "tick" {
+		t.Errorf("stream should resume after the last event id, got %+v", e)
+	}
+
+	cancel()
+	stream.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serve should shut down cleanly, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("serve did not return after the client disconnected")
+	}
+}
+
+func TestServeOutlivesServerTimeouts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := make(chan sse.Event)
+		go func() {
+			defer close(events)
+			for i := range 6 {
+				time.Sleep(50 * time.Millisecond)
+				events <- sse.Event{ID: strconv.Itoa(i), Data: "tick"}
+			}
+		}()
+		_ = sse.Serve(w, r, events, sse.Options{})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = server.Run(ctx, handler, server.Options{
+			Listener:     ln,
+			ReadTimeout:  100 * time.Millisecond,
+			WriteTimeout: 100 * time.Millisecond,
+			Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+		})
+	}()
+
+	stream, err := sse.Connect(ctx, http.DefaultClient, "http://"+ln.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer stream.Close()
+
+	for i := range 6 {
+		e, err := stream.Next()
+		if err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if e.ID != strconv.Itoa(i) {
+			t.Errorf("got event %q, want %d", e.ID, i)
+		}
+	}
+}
+
+func TestServeDropsStalledClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	served := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := make(chan sse.Event)
+		go func() {
+			data := strings.Repeat("x", 64<<10)
+			for {
+				select {
+				case events <- sse.Event{Data: data}:
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}()
+		_ = sse.Serve(w, r, events, sse.Options{WriteTimeout: 100 * time.Millisecond})
+		close(served)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = server.Run(ctx, handler, server.Options{
+			Listener: ln,
+			Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		})
+	}()
+
+	// The client sends a request and never reads the response.
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := io.WriteString(conn, "GET / HTTP/1.1\r\nHost: test\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-served:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the stream kept writing to a client that stopped reading")
+	}
+}
@@ -0,0 +1,63 @@
+// Package expiring holds entries that expire, for the in-memory stores.
+package expiring
+
+import "time"
+
+// Map is a map whose entries expire. Expired entries are hidden at once and
+// evicted by Sweep, so memory does not grow with keys that are never read
+// again. It is not safe for concurrent use; stores guard it with their lock.
+type Map[V any] struct {
+	entries   map[string]entry[V]
+	lastSweep time.Time
+}
+
+type entry[V any] struct {
+	value  V
+	expiry time.Time
+}
+
+// Creates an empty Map
+func New[V any]() *Map[V] {
+	return &Map[V]{
+		entries:   make(map[string]entry[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the value of key if it has not expired by now.
+func (m *Map[V]) Get(key string, now time.Time) (V, bool) {
+	e, ok := m.entries[key]
+	if !ok || !now.Before(e.expiry) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores the value of key until expiry.
+func (m *Map[V]) Set(key string, value V, expiry time.Time) {
+	m.entries[key] = entry[V]{value: value, expiry: expiry}
+}
+
+// Delete removes key.
+func (m *Map[V]) Delete(key string) {
+	delete(m.entries, key)
+}
+
+// Len returns the number of entries, including expired ones not yet evicted.
+func (m *Map[V]) Len() int {
+	return len(m.entries)
+}
+
+// Sweep evicts the expired entries once interval has passed since the last sweep.
+func (m *Map[V]) Sweep(now time.Time, interval time.Duration) {
+	if now.Sub(m.lastSweep) < interval {
+		return
+	}
+	for key, e := range m.entries {
+		if !now.Before(e.expiry) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
@@ -0,0 +1,40 @@
+package expiring_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ferdiebergado/gopherkit/internal/expiring"
+)
+
+func TestMap(t *testing.T) {
+	m := expiring.New[int]()
+	now := time.Now()
+
+	m.Set("a", 1, now.Add(time.Minute))
+	m.Set("b", 2, now.Add(time.Second))
+
+	if v, ok := m.Get("a", now); !ok || v != 1 {
+		t.Errorf("get a: got %d, %v", v, ok)
+	}
+
+	later := now.Add(2 * time.Second)
+	if _, ok := m.Get("b", later); ok {
+		t.Error("expired entry b was returned")
+	}
+
+	m.Sweep(later, time.Hour)
+	if m.Len() != 2 {
+		t.Errorf("swept before the interval passed, got %d entries", m.Len())
+	}
+
+	m.Sweep(later, 0)
+	if m.Len() != 1 {
+		t.Errorf("got %d entries after sweep, want 1", m.Len())
+	}
+
+	m.Delete("a")
+	if _, ok := m.Get("a", now); ok {
+		t.Error("deleted entry a was returned")
+	}
+}
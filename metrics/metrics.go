@@ -0,0 +1,289 @@
+// Package metrics records counters, gauges and histograms and exposes them
+// in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Metric types
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// DefaultBuckets suit request latencies in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var (
+	validName  = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	validLabel = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// collector writes one or more metric families.
+type collector interface {
+	names() []string
+	collect() []family
+}
+
+// Registry holds metrics and writes them in the text format.
+// Registering a name twice, or an invalid name, panics.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+	names      map[string]bool
+}
+
+// Creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range c.names() {
+		if !validName.MatchString(name) {
+			panic(fmt.Sprintf("metrics: invalid name %q", name))
+		}
+		if r.names[name] {
+			panic(fmt.Sprintf("metrics: %s is already registered", name))
+		}
+	}
+	for _, name := range c.names() {
+		r.names[name] = true
+	}
+	r.collectors = append(r.collectors, c)
+}
+
+// Counter registers a counter partitioned by the given labels.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{vec: newVec(name, help, TypeCounter, labels, nil)}
+	r.register(c.vec)
+	return c
+}
+
+// Gauge registers a gauge partitioned by the given labels.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{vec: newVec(name, help, TypeGauge, labels, nil)}
+	r.register(g.vec)
+	return g
+}
+
+// Histogram registers a histogram partitioned by the given labels.
+// Nil buckets select DefaultBuckets.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &Histogram{vec: newVec(name, help, TypeHistogram, labels, buckets)}
+	r.register(h.vec)
+	return h
+}
+
+// GaugeFunc registers a gauge whose value is read from fn on every scrape.
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(&funcCollector{name: name, help: help, typ: TypeGauge, fn: fn})
+}
+
+// CounterFunc registers a counter whose value is read from fn on every scrape.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(&funcCollector{name: name, help: help, typ: TypeCounter, fn: fn})
+}
+
+// Counter is a value that only goes up.
+type Counter struct {
+	vec *vec
+}
+
+// Inc adds one to the series with the given label values.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series with the given label values.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.vec.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Gauge is a value that goes up and down.
+type Gauge struct {
+	vec *vec
+}
+
+// Set sets the series with the given label values to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.vec.update(labelValues, func(s *series) { s.value = v })
+}
+
+// Add adds v, which may be negative, to the series with the given label values.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.vec.update(labelValues, func(s *series) { s.value += v })
+}
+
+// Inc adds one to the series with the given label values.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts one from the series with the given label values.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	vec *vec
+}
+
+// Observe records v in the series with the given label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	buckets := h.vec.buckets
+	i := sort.SearchFloat64s(buckets, v)
+
+	h.vec.update(labelValues, func(s *series) {
+		if s.counts == nil {
+			s.counts = make([]uint64, len(buckets))
+		}
+		if i < len(buckets) {
+			s.counts[i]++
+		}
+		s.value += v
+		s.count++
+	})
+}
+
+// vec is a metric family with one series per combination of label values.
+type vec struct {
+	name, help, typ string
+	labels          []string
+	buckets         []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is a single time series. Histograms store per-bucket counts,
+// their sum in value and the number of observations in count.
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64
+	count       uint64
+}
+
+func newVec(name, help, typ string, labels []string, buckets []float64) *vec {
+	for _, l := range labels {
+		if !validLabel.MatchString(l) || strings.HasPrefix(l, "__") || l == "le" {
+			panic(fmt.Sprintf("metrics: %s: invalid label %q", name, l))
+		}
+	}
+	return &vec{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+}
+
+func (v *vec) update(labelValues []string, fn func(*series)) {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		v.series[key] = s
+	}
+	fn(s)
+}
+
+func (v *vec) names() []string {
+	return []string{v.name}
+}
+
+func (v *vec) collect() []family {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	f := family{name: v.name, help: v.help, typ: v.typ}
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := v.series[k]
+		if v.typ != TypeHistogram {
+			f.samples = append(f.samples, sample{name: v.name, labels: v.pairs(s, "", 0), value: s.value})
+			continue
+		}
+
+		var cumulative uint64
+		for i, bound := range v.buckets {
+			if s.counts != nil {
+				cumulative += s.counts[i]
+			}
+			f.samples = append(f.samples, sample{name: v.name + "_bucket", labels: v.pairs(s, "le", bound), value: float64(cumulative)})
+		}
+		f.samples = append(f.samples,
+			sample{name: v.name + "_bucket", labels: v.pairs(s, "le", math.Inf(1)), value: float64(s.count)},
+			sample{name: v.name + "_sum", labels: v.pairs(s, "", 0), value: s.value},
+			sample{name: v.name + "_count", labels: v.pairs(s, "", 0), value: float64(s.count)},
+		)
+	}
+
+	return []family{f}
+}
+
+// pairs returns the labels of a series, followed by extra when it is set.
+func (v *vec) pairs(s *series, extra string, extraValue float64) []labelPair {
+	pairs := make([]labelPair, 0, len(v.labels)+1)
+	for i, l := range v.labels {
+		pairs = append(pairs, labelPair{l, s.labelValues[i]})
+	}
+	if extra != "" {
+		pairs = append(pairs, labelPair{extra, formatFloat(extraValue)})
+	}
+	return pairs
+}
+
+// funcCollector reads a single unlabeled value when collected.
+type funcCollector struct {
+	name, help, typ string
+	fn              func() float64
+}
+
+func (c *funcCollector) names() []string {
+	return []string{c.name}
+}
+
+func (c *funcCollector) collect() []family {
+	return []family{{
+		name:    c.name,
+		help:    c.help,
+		typ:     c.typ,
+		samples: []sample{{name: c.name, value: c.fn()}},
+	}}
+}
@@ -0,0 +1,97 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/metrics"
+)
+
+func TestWriteText(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	jobs := reg.Counter("jobs_total", "Processed jobs.", "queue", "result")
+	jobs.Inc("mail", "ok")
+	jobs.Add(2, "mail", "ok")
+	jobs.Inc("sms", `bad "quote"`)
+
+	temp := reg.Gauge("temperature_celsius", "Current temperature.\nIn Celsius.")
+	temp.Set(21.5)
+	temp.Dec()
+
+	latency := reg.Histogram("latency_seconds", "", []float64{1, 0.1}, "op")
+	latency.Observe(0.05, "read")
+	latency.Observe(0.5, "read")
+	latency.Observe(3, "read")
+
+	reg.GaugeFunc("answer", "The answer.", func() float64 { return 42 })
+
+	var sb strings.Builder
+	if err := reg.WriteText(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# HELP answer The answer.
+# TYPE answer gauge
+answer 42
+# HELP jobs_total Processed jobs.
+# TYPE jobs_total counter
+jobs_total{queue="mail",result="ok"} 3
+jobs_total{queue="sms",result="bad \"quote\""} 1
+# TYPE latency_seconds histogram
+latency_seconds_bucket{op="read",le="0.1"} 1
+latency_seconds_bucket{op="read",le="1"} 2
+latency_seconds_bucket{op="read",le="+Inf"} 3
+latency_seconds_sum{op="read"} 3.55
+latency_seconds_count{op="read"} 3
+# HELP temperature_celsius Current temperature.\nIn Celsius.
+# TYPE temperature_celsius gauge
+temperature_celsius 20.5
+`
+	if got := sb.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	tests := map[string]func(*metrics.Registry){
+		"duplicate name": func(reg *metrics.Registry) {
+			reg.Counter("a_total", "")
+			reg.Gauge("a_total", "")
+		},
+		"invalid name":      func(reg *metrics.Registry) { reg.Counter("a-b", "") },
+		"invalid label":     func(reg *metrics.Registry) { reg.Counter("a", "", "le") },
+		"label count":       func(reg *metrics.Registry) { reg.Counter("a", "", "x").Inc() },
+		"negative increase": func(reg *metrics.Registry) { reg.Counter("a", "").Add(-1) },
+	}
+
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			fn(metrics.NewRegistry())
+		})
+	}
+}
+
+func TestHandlerWithRuntime(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.RegisterRuntime()
+
+	rr := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rr.Header().Get("Content-Type"); got != metrics.ContentType {
+		t.Errorf("got content type %q", got)
+	}
+	for _, name := range []string{"go_goroutines ", "go_memstats_alloc_bytes ", "go_gc_cycles_total "} {
+		if !strings.Contains(rr.Body.String(), "\n"+name) {
+			t.Errorf("missing %s in\n%s", name, rr.Body.String())
+		}
+	}
+}
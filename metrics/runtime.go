@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"runtime"
+	"time"
+)
+
+// RegisterRuntime adds Go runtime metrics: goroutines, threads, memory and
+// garbage collection statistics.
+func (r *Registry) RegisterRuntime() {
+	r.register(runtimeCollector{})
+}
+
+// runtimeCollector reads the memory statistics once per scrape, since
+// reading them briefly stops the world.
+type runtimeCollector struct{}
+
+var runtimeMetrics = []struct {
+	name, help, typ string
+	value           func(*runtime.MemStats) float64
+}{
+	{"go_memstats_alloc_bytes", "Bytes of allocated heap objects.", TypeGauge,
+		func(m *runtime.MemStats) float64 { return float64(m.Alloc) }},
+	{"go_memstats_alloc_bytes_total", "Cumulative bytes allocated for heap objects.", TypeCounter,
+		func(m *runtime.MemStats) float64 { return float64(m.TotalAlloc) }},
+	{"go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", TypeGauge,
+		func(m *runtime.MemStats) float64 { return float64(m.Sys) }},
+	{"go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", TypeGauge,
+		func(m *runtime.MemStats) float64 { return float64(m.HeapInuse) }},
+	{"go_memstats_heap_objects", "Number of allocated heap objects.", TypeGauge,
+		func(m *runtime.MemStats) float64 { return float64(m.HeapObjects) }},
+	{"go_gc_cycles_total", "Number of completed GC cycles.", TypeCounter,
+		func(m *runtime.MemStats) float64 { return float64(m.NumGC) }},
+	{"go_gc_pause_seconds_total", "Cumulative time spent in GC stop-the-world pauses.", TypeCounter,
+		func(m *runtime.MemStats) float64 { return time.Duration(m.PauseTotalNs).Seconds() }},
+}
+
+func (runtimeCollector) names() []string {
+	names := []string{"go_goroutines", "go_threads"}
+	for _, m := range runtimeMetrics {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+func (runtimeCollector) collect() []family {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	threads, _ := runtime.ThreadCreateProfile(nil)
+
+	families := []family{
+		gaugeFamily("go_goroutines", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine())),
+		gaugeFamily("go_threads", "Number of OS threads created.", float64(threads)),
+	}
+	for _, m := range runtimeMetrics {
+		families = append(families, family{
+			name:    m.name,
+			help:    m.help,
+			typ:     m.typ,
+			samples: []sample{{name: m.name, value: m.value(&ms)}},
+		})
+	}
+	return families
+}
+
+func gaugeFamily(name, help string, v float64) family {
+	return family{name: name, help: help, typ: TypeGauge, samples: []sample{{name: name, value: v}}}
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	ghttp "github.com/ferdiebergado/gopherkit/http"
+)
+
+// ContentType is the media type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type family struct {
+	name, help, typ string
+	samples         []sample
+}
+
+type sample struct {
+	name   string
+	labels []labelPair
+	value  float64
+}
+
+type labelPair struct {
+	name, value string
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// WriteText writes every metric in the Prometheus text exposition format,
+// sorted by name.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+
+	var families []family
+	for _, c := range collectors {
+		families = append(families, c.collect()...)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if f.help != "" {
+			bw.WriteString("# HELP " + f.name + " " + helpEscaper.Replace(f.help) + "\n")
+		}
+		bw.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+
+		for _, s := range f.samples {
+			bw.WriteString(s.name)
+			if len(s.labels) > 0 {
+				bw.WriteByte('{')
+				for i, l := range s.labels {
+					if i > 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(l.name + `="` + labelEscaper.Replace(l.value) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + formatFloat(s.value) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves the metrics for scraping.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(ghttp.HeaderContentType, ContentType)
+		if err := r.WriteText(w); err != nil {
+			slog.Debug("write metrics", "reason", err)
+		}
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,71 @@
+package signer_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ferdiebergado/gopherkit/signer"
+)
+
+func TestSignVerify(t *testing.T) {
+	s := signer.New([]byte("0123456789abcdef0123456789abcdef"), "test")
+
+	token := s.Sign([]byte(`{"page":2}`))
+
+	payload, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if string(payload) != `{"page":2}` {
+		t.Errorf("got payload %q", payload)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	s := signer.New([]byte("0123456789abcdef0123456789abcdef"), "test")
+	other := signer.New([]byte("fedcba9876543210fedcba9876543210"), "test")
+	token := s.Sign([]byte("payload"))
+	encoded, sig, _ := strings.Cut(token, ".")
+
+	tests := map[string]string{
+		"other key":       other.Sign([]byte("payload")),
+		"changed payload": base64.RawURLEncoding.EncodeToString([]byte("other")) + "." + sig,
+		"missing dot":     encoded + sig,
+		"empty":           "",
+		"garbage":         "!!!.???",
+		"truncated mac":   encoded + "." + sig[:10],
+	}
+
+	for name, tok := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Verify(tok); !errors.Is(err, signer.ErrInvalidSignature) {
+				t.Errorf("got error %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherPurpose(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	cursor := signer.New(key, "cursor")
+	csrf := signer.New(key, "csrf")
+
+	if _, err := csrf.Verify(cursor.Sign([]byte("payload"))); !errors.Is(err, signer.ErrInvalidSignature) {
+		t.Errorf("got error %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestNewRejectsShortKey(t *testing.T) {
+	for _, key := range [][]byte{nil, []byte("too short")} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("New with a %d byte key did not panic", len(key))
+				}
+			}()
+			signer.New(key, "test")
+		}()
+	}
+}